@@ -48,14 +48,6 @@ resource "aws_security_group" "this" {
 
   vpc_id = var.vpc.vpc_id
 
-  ingress {
-    from_port   = 0
-    to_port     = 65535
-    protocol    = "tcp"
-    cidr_blocks = ["0.0.0.0/0"]
-    description = "Allow all inbound traffic"
-  }
-
   egress {
     from_port        = 0
     to_port          = 0
@@ -81,66 +73,58 @@ data "greynoise_personas" "rdp" {
   limit  = 1
 }
 
-resource "greynoise_sensor_bootstrap" "this" {
-  public_ip = aws_instance.this.public_ip
+data "greynoise_persona_ingress_rules" "rdp" {
+  persona_id = data.greynoise_personas.rdp.ids[0]
+}
 
-  config = {
-    # using config to comply with destroy provisioners only
-    # referencing  'self', 'count.index' or 'each.key' only in destroy provisioners
-    ssh_private_key = sensitive(file(var.key_pair.private_key_file))
-    public_ip       = aws_instance.this.public_ip
+# only the ports the persona listens on are opened
+resource "aws_vpc_security_group_ingress_rule" "persona" {
+  for_each = {
+    for rule in data.greynoise_persona_ingress_rules.rdp.rules :
+    "${rule.protocol}-${rule.from_port}-${rule.to_port}" => rule
   }
 
-  provisioner "remote-exec" {
-    connection {
-      host = self.config.public_ip
-      user = "ubuntu"
-      port = 22
+  security_group_id = aws_security_group.this.id
+  ip_protocol       = each.value.protocol
+  from_port         = each.value.from_port
+  to_port           = each.value.to_port
+  cidr_ipv4         = "0.0.0.0/0"
+  description       = "Persona traffic"
+}
+
+resource "aws_vpc_security_group_ingress_rule" "ssh" {
+  security_group_id = aws_security_group.this.id
+  ip_protocol       = "tcp"
+  from_port         = 22
+  to_port           = 22
+  cidr_ipv4         = "0.0.0.0/0"
+  description       = "SSH before bootstrap"
+}
 
-      private_key = self.config.ssh_private_key
-    }
+resource "aws_vpc_security_group_ingress_rule" "sensor_ssh" {
+  security_group_id = aws_security_group.this.id
+  ip_protocol       = "tcp"
+  from_port         = provider::greynoise::ssh_port(aws_instance.this.public_ip)
+  to_port           = provider::greynoise::ssh_port(aws_instance.this.public_ip)
+  cidr_ipv4         = "0.0.0.0/0"
+  description       = "SSH after bootstrap"
+}
 
-    inline = [
-      # ensure that script can run by waiting for cloud-init to complete
-      "cloud-init status --wait > /dev/null",
-      self.setup_script,
-    ]
-  }
+resource "greynoise_sensor_bootstrap" "this" {
+  public_ip = aws_instance.this.public_ip
 
-  provisioner "remote-exec" {
-    connection {
-      host = self.config.public_ip
-      user = "ubuntu"
-      port = 22
-
-      private_key = self.config.ssh_private_key
-    }
-
-    inline = [
-      self.bootstrap_script,
-    ]
-    # failure is expected as SSH connection will be lost
-    # once bootstrap completes and changes SSH port
-    on_failure = continue
+  # the provider connects over SSH to bootstrap the server on create
+  # and to unbootstrap it on destroy
+  ssh = {
+    host        = aws_instance.this.public_ip
+    user        = "ubuntu"
+    private_key = file(var.key_pair.private_key_file)
   }
 
-  provisioner "remote-exec" {
-    connection {
-      host = self.config.public_ip
-      user = "ubuntu"
-      port = self.ssh_port_selected
-
-      private_key = self.config.ssh_private_key
-    }
-
-    when = destroy
-    inline = [
-      self.unbootstrap_script,
-    ]
-    # failure is expected as SSH connection will be lost
-    # once unbootstrap completes and changes SSH port
-    on_failure = continue
-  }
+  depends_on = [
+    aws_vpc_security_group_ingress_rule.ssh,
+    aws_vpc_security_group_ingress_rule.sensor_ssh,
+  ]
 }
 
 data "greynoise_sensor" "this" {
@@ -0,0 +1,3 @@
+data "greynoise_persona_ingress_rules" "this" {
+  persona_id = "ac65d8a0-ed21-417e-a1a2-65a4e09c3144"
+}
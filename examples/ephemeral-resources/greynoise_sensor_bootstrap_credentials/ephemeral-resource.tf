@@ -0,0 +1,39 @@
+# the API key is only available during the run and is not persisted in the state
+ephemeral "greynoise_sensor_bootstrap_credentials" "this" {}
+
+resource "greynoise_sensor_bootstrap" "this" {
+  public_ip = "44.13.34.10"
+
+  provisioner "remote-exec" {
+    connection {
+      host = "44.13.34.10"
+      user = "ubuntu"
+      port = 22
+
+      private_key = file("~/.ssh/id_ed25519")
+    }
+
+    inline = [
+      # ensure that script can run by waiting for cloud-init to complete
+      "cloud-init status --wait > /dev/null",
+      ephemeral.greynoise_sensor_bootstrap_credentials.this.setup_script,
+    ]
+  }
+
+  provisioner "remote-exec" {
+    connection {
+      host = "44.13.34.10"
+      user = "ubuntu"
+      port = 22
+
+      private_key = file("~/.ssh/id_ed25519")
+    }
+
+    inline = [
+      self.bootstrap_script,
+    ]
+    # failure is expected as SSH connection will be lost
+    # once bootstrap completes and changes SSH port
+    on_failure = continue
+  }
+}
@@ -0,0 +1,4 @@
+locals {
+  # sudo bash "$F" -k $KEY -p 185.108.182.240 -i 10.0.0.10 -s 62914
+  bootstrap_command = provider::greynoise::bootstrap_command("185.108.182.240", "10.0.0.10", null, null)
+}
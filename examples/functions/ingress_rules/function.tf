@@ -0,0 +1,6 @@
+locals {
+  # [{ protocol = "tcp", from_port = 80, to_port = 80 }, { protocol = "tcp", from_port = 3389, to_port = 3389 }]
+  ingress_rules = provider::greynoise::ingress_rules(
+    concat(data.greynoise_persona_ingress_rules.rdp.ports, data.greynoise_persona_ingress_rules.http.ports)
+  )
+}
@@ -0,0 +1,4 @@
+locals {
+  # ["179.108.182.240", "172.108.182.241"]
+  sensor_public_ips = provider::greynoise::parse_ips("179.108.182.240/32,172.108.182.241/32")
+}
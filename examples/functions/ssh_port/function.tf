@@ -0,0 +1,8 @@
+resource "aws_security_group_rule" "sensor_ssh" {
+  type              = "ingress"
+  security_group_id = aws_security_group.this.id
+  protocol          = "tcp"
+  from_port         = provider::greynoise::ssh_port(aws_eip.this.public_ip)
+  to_port           = provider::greynoise::ssh_port(aws_eip.this.public_ip)
+  cidr_blocks       = ["0.0.0.0/0"]
+}
@@ -0,0 +1,5 @@
+resource "greynoise_sensor_fleet_persona" "this" {
+  sensor_filter   = "honeypot-eu"
+  persona_id      = "fa2d48c3-b2b0-4140-b045-7795fc04a880"
+  max_concurrency = 20
+}
@@ -0,0 +1,4 @@
+# Sensors can be imported by UUID, public IP or name.
+terraform import greynoise_sensor_persona.this 62b4137d-2538-4fc1-8fcf-f8d855ddeeaf
+terraform import greynoise_sensor_persona.this 159.223.200.217
+terraform import greynoise_sensor_persona.this "Gifted Trout"
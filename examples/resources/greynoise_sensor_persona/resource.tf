@@ -2,3 +2,8 @@ data "greynoise_sensor_persona" "this" {
   sensor_id  = "62b4137d-2538-4fc1-8fcf-f8d855ddeeaf"
   persona_id = "fa2d48c3-b2b0-4140-b045-7795fc04a880"
 }
+
+resource "greynoise_sensor_persona" "by_name" {
+  sensor_id    = "62b4137d-2538-4fc1-8fcf-f8d855ddeeaf"
+  persona_name = "Cisco ASA"
+}
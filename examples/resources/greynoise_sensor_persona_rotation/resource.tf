@@ -0,0 +1,9 @@
+resource "greynoise_sensor_persona_rotation" "this" {
+  sensor_id = "62b4137d-2538-4fc1-8fcf-f8d855ddeeaf"
+  persona_ids = [
+    "fa2d48c3-b2b0-4140-b045-7795fc04a880",
+    "501c5e5a-cf2e-4401-844a-04d4391b1332",
+  ]
+  rotation_period = "168h"
+  start_time      = "2024-09-02T00:00:00Z"
+}
@@ -0,0 +1,2 @@
+# Sensors can be imported by UUID, public IP or name.
+terraform import greynoise_sensor_state.this 62b4137d-2538-4fc1-8fcf-f8d855ddeeaf
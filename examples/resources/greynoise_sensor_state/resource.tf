@@ -0,0 +1,4 @@
+resource "greynoise_sensor_state" "this" {
+  sensor_id = "62b4137d-2538-4fc1-8fcf-f8d855ddeeaf"
+  disabled  = true
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveFields are the substrings of request body fields whose values are redacted in the audit log.
+var sensitiveFields = []string{"key", "token", "secret", "password"}
+
+// Outcomes of requests recorded in the audit log.
+const (
+	AuditOutcomeSent    = "sent"
+	AuditOutcomeRefused = "refused"
+	AuditOutcomeDryRun  = "dry_run"
+)
+
+// AuditEntry is a line of the audit log, recorded for each request that changes resources.
+type AuditEntry struct {
+	Time       time.Time       `json:"time"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	SensorID   string          `json:"sensor_id,omitempty"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Outcome    string          `json:"outcome"`
+	Status     int             `json:"status,omitempty"`
+	DurationMS int64           `json:"duration_ms"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// auditLog appends entries as JSON lines to a file, safe for concurrent use.
+type auditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// check verifies that the audit log can be written, creating it if needed.
+func (l *auditLog) check() error {
+	f, err := l.open()
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// record appends an entry to the audit log. The file is opened for each entry, so that it can be rotated.
+func (l *auditLog) record(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := l.open()
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+func (l *auditLog) open() (*os.File, error) {
+	return os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+}
+
+// redactJSON replaces the values of sensitive fields in a JSON body. Bodies that are not JSON are
+// dropped entirely, as they cannot be redacted.
+func redactJSON(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return json.RawMessage(`"` + redacted + `"`)
+	}
+
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return nil
+	}
+
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for name, value := range v {
+			if isSensitiveField(name) {
+				v[name] = redacted
+			} else {
+				v[name] = redactValue(value)
+			}
+		}
+
+		// Name/value pairs, such as sensor metadata, are redacted by name.
+		if name, ok := v["name"].(string); ok && isSensitiveField(name) {
+			if _, ok := v["val"]; ok {
+				v["val"] = redacted
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+	}
+
+	return v
+}
+
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, field := range sensitiveFields {
+		if strings.Contains(name, field) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package client_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+func TestGreyNoiseClient_AuditLog(t *testing.T) {
+	const sensors = 10
+
+	ctrl := gomock.NewController(t)
+	mockHTTPClient := client.NewMockHTTPClient(ctrl)
+
+	mockHTTPClient.EXPECT().
+		Do(gomock.Any()).
+		DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodGet {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body: responseBody(`{
+					  "user_id": "4c65d8a0-ed21-417e-a1a2-65a4e09c3144",
+					  "workspace_id": "7c65d8a0-ed21-417e-a1a2-65a4e09c3144"
+					}`),
+				}, nil
+			}
+
+			return &http.Response{StatusCode: http.StatusAccepted}, nil
+		}).
+		Times(sensors + 1)
+
+	auditLogPath := filepath.Join(t.TempDir(), "audit.log")
+
+	gClient, err := client.New("test-7037403284",
+		client.WithHTTPClient(mockHTTPClient), client.WithAuditLogPath(auditLogPath))
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < sensors; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, gClient.UpdateSensor(context.Background(), "ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+				client.SensorUpdateRequest{
+					Metadata: &client.SensorMetadata{
+						Items: []client.SensorMetadatum{
+							{Access: client.MetadataAccessReadonly, Name: "provider", Val: "greynoise"},
+							{Access: client.MetadataAccessReadonly, Name: "api_token", Val: "secret"},
+						},
+					},
+				}))
+		}()
+	}
+
+	wg.Wait()
+
+	f, err := os.Open(auditLogPath)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var lines int
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines++
+
+		var entry client.AuditEntry
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+
+		assert.Equal(t, http.MethodPut, entry.Method)
+		assert.Equal(t, "/v1/workspaces/7c65d8a0-ed21-417e-a1a2-65a4e09c3144/sensors/"+
+			"ac65d8a0-ed21-417e-a1a2-65a4e09c3144", entry.Path)
+		assert.Equal(t, "ac65d8a0-ed21-417e-a1a2-65a4e09c3144", entry.SensorID)
+		assert.Equal(t, client.AuditOutcomeSent, entry.Outcome)
+		assert.Equal(t, http.StatusAccepted, entry.Status)
+		assert.False(t, entry.Time.IsZero())
+		assert.JSONEq(t, `{
+		  "metadata": {
+			"items": [
+			  {"access": "readonly", "name": "provider", "val": "greynoise"},
+			  {"access": "readonly", "name": "api_token", "val": "REDACTED"}
+			]
+		  }
+		}`, string(entry.Request))
+	}
+
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, sensors, lines)
+}
+
+func TestGreyNoiseClient_AuditLogOutcome(t *testing.T) {
+	testCases := []struct {
+		name    string
+		option  client.Option
+		outcome string
+		error   string
+	}{
+		{
+			name:    "read-only",
+			option:  client.WithReadOnly(true),
+			outcome: client.AuditOutcomeRefused,
+			error:   client.ErrReadOnly.Error(),
+		},
+		{
+			name:    "dry run",
+			option:  client.WithDryRun(true),
+			outcome: client.AuditOutcomeDryRun,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockHTTPClient := client.NewMockHTTPClient(ctrl)
+
+			// Only the account is requested, the update is not sent.
+			mockHTTPClient.EXPECT().
+				Do(gomock.Any()).
+				Return(&http.Response{
+					StatusCode: http.StatusOK,
+					Body: responseBody(`{
+					  "user_id": "4c65d8a0-ed21-417e-a1a2-65a4e09c3144",
+					  "workspace_id": "7c65d8a0-ed21-417e-a1a2-65a4e09c3144"
+					}`),
+				}, nil)
+
+			auditLogPath := filepath.Join(t.TempDir(), "audit.log")
+
+			gClient, err := client.New("test-7037403284", client.WithHTTPClient(mockHTTPClient),
+				client.WithAuditLogPath(auditLogPath), tc.option)
+			assert.NoError(t, err)
+
+			err = gClient.UpdateSensor(context.Background(), "ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+				client.SensorUpdateRequest{Persona: "bc65d8a0-ed21-417e-a1a2-65a4e09c3144"})
+			if tc.error != "" {
+				assert.EqualError(t, err, tc.error)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			b, err := os.ReadFile(auditLogPath)
+			assert.NoError(t, err)
+
+			var entry client.AuditEntry
+			assert.NoError(t, json.Unmarshal(b, &entry))
+
+			assert.Equal(t, http.MethodPut, entry.Method)
+			assert.Equal(t, "ac65d8a0-ed21-417e-a1a2-65a4e09c3144", entry.SensorID)
+			assert.Equal(t, tc.outcome, entry.Outcome)
+			assert.Equal(t, tc.error, entry.Error)
+			assert.Zero(t, entry.Status)
+			assert.JSONEq(t, `{"persona": "bc65d8a0-ed21-417e-a1a2-65a4e09c3144"}`, string(entry.Request))
+		})
+	}
+}
+
+func TestGreyNoiseClient_AuditLogError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockHTTPClient := client.NewMockHTTPClient(ctrl)
+
+	_, err := client.New("test-7037403284", client.WithHTTPClient(mockHTTPClient),
+		client.WithAuditLogPath(filepath.Join(t.TempDir(), "missing", "audit.log")))
+	assert.ErrorContains(t, err, "audit log error")
+}
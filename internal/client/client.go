@@ -5,19 +5,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/mitchellh/mapstructure"
 )
 
 const (
-	HeaderKey = "key"
+	HeaderKey               = "key"
+	HeaderIfUnmodifiedSince = "If-Unmodified-Since"
 
 	retryCount                   = 3
+	defaultRequestTimeout        = 30 * time.Second
+	defaultPollInterval          = 5 * time.Second
 	defaultPersonaSearchPageSize = int32(100)
 )
 
@@ -34,6 +40,13 @@ type GreyNoiseClient struct {
 	apiKey     string
 	account    Account
 	httpClient HTTPClient
+	readOnly   bool
+	dryRun     bool
+	auditLog   *auditLog
+
+	pollInterval time.Duration
+	sensorLocks  keyedMutex
+	ownUpdates   sync.Map
 }
 
 // New is the preferred way to instantiate the GreyNoiseClient.
@@ -50,15 +63,24 @@ func New(apiKey string, options ...Option) (*GreyNoiseClient, error) {
 		client.baseURL = defaultBaseURL
 	}
 
+	if client.pollInterval == 0 {
+		client.pollInterval = defaultPollInterval
+	}
+
 	if client.httpClient == nil {
 		retryClient := retryablehttp.NewClient()
 		retryClient.RetryMax = retryCount
 		retryClient.Logger = nil
+		// The last response is returned once retries are exhausted, so that its status is reported.
+		retryClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
 
-		httpClient := retryClient.StandardClient()
-		httpClient.Timeout = time.Second * 30
+		client.httpClient = retryClient.StandardClient()
+	}
 
-		client.httpClient = httpClient
+	if client.auditLog != nil {
+		if err := client.auditLog.check(); err != nil {
+			return nil, fmt.Errorf("audit log error: %w", err)
+		}
 	}
 
 	acct, err := client.getAccount()
@@ -75,6 +97,16 @@ func (c *GreyNoiseClient) WorkspaceID() uuid.UUID {
 	return c.account.WorkspaceID
 }
 
+// ReadOnly returns whether requests that change resources are refused.
+func (c *GreyNoiseClient) ReadOnly() bool {
+	return c.readOnly
+}
+
+// DryRun returns whether requests that change resources are only logged.
+func (c *GreyNoiseClient) DryRun() bool {
+	return c.dryRun
+}
+
 func (c *GreyNoiseClient) UserID() uuid.UUID {
 	return c.account.UserID
 }
@@ -87,7 +119,10 @@ type Account struct {
 func (c *GreyNoiseClient) getAccount() (*Account, error) {
 	u := c.baseURL.ResolveReference(&url.URL{Path: "/v1/account"})
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+	ctx, cancel := withDefaultTimeout(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +130,7 @@ func (c *GreyNoiseClient) getAccount() (*Account, error) {
 	c.setAuthHeader(req)
 	c.setJSONContentHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(ctx, "", req, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +150,9 @@ func (c *GreyNoiseClient) getAccount() (*Account, error) {
 func (c *GreyNoiseClient) GetPersona(ctx context.Context, id string) (*Persona, error) {
 	u := c.baseURL.ResolveReference(&url.URL{Path: fmt.Sprintf("/v1/personas/%s", id)})
 
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
@@ -123,7 +161,7 @@ func (c *GreyNoiseClient) GetPersona(ctx context.Context, id string) (*Persona,
 	c.setAuthHeader(req)
 	c.setJSONContentHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(ctx, "", req, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -170,6 +208,9 @@ func (c *GreyNoiseClient) PersonasSearch(ctx context.Context, filters PersonaSea
 		}
 	}
 
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
@@ -179,7 +220,7 @@ func (c *GreyNoiseClient) PersonasSearch(ctx context.Context, filters PersonaSea
 	c.setAuthHeader(req)
 	c.setJSONContentHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(ctx, "", req, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -200,6 +241,9 @@ func (c *GreyNoiseClient) GetSensor(ctx context.Context, id string) (*Sensor, er
 	u := c.baseURL.ResolveReference(&url.URL{Path: fmt.Sprintf("/v1/workspaces/%s/sensors/%s",
 		c.WorkspaceID(), id)})
 
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
@@ -208,7 +252,7 @@ func (c *GreyNoiseClient) GetSensor(ctx context.Context, id string) (*Sensor, er
 	c.setAuthHeader(req)
 	c.setJSONContentHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(ctx, "", req, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -229,7 +273,39 @@ func (c *GreyNoiseClient) GetSensor(ctx context.Context, id string) (*Sensor, er
 	return &result, nil
 }
 
+// ConditionalUpdateSensor updates the sensor only if it was not modified since the given time, other than by
+// this client, returning ErrConflict otherwise. The last update time of the sensor is compared at full
+// precision before the update, which is also sent with If-Unmodified-Since so that the API rejects it if
+// the sensor was modified in the meantime. The header only has a precision of a second, so it is rounded up
+// not to reject a sensor unmodified since, changes within that second being caught by the comparison.
+func (c *GreyNoiseClient) ConditionalUpdateSensor(ctx context.Context, id string, request SensorUpdateRequest,
+	unmodifiedSince time.Time) error {
+	sensor, err := c.GetSensor(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// The last modification was made by this client, e.g. by another resource of the same sensor.
+	if own, ok := c.ownUpdates.Load(id); ok {
+		if ownUpdatedAt, ok := own.(time.Time); ok && sensor.UpdatedAt.Equal(ownUpdatedAt) {
+			unmodifiedSince = sensor.UpdatedAt
+		}
+	}
+
+	if sensor.UpdatedAt.After(unmodifiedSince) {
+		return fmt.Errorf("%w: sensor %s was updated at %s, after %s", ErrConflict, id,
+			sensor.UpdatedAt.Format(time.RFC3339Nano), unmodifiedSince.Format(time.RFC3339Nano))
+	}
+
+	return c.updateSensor(ctx, id, request, unmodifiedSince)
+}
+
 func (c *GreyNoiseClient) UpdateSensor(ctx context.Context, id string, request SensorUpdateRequest) error {
+	return c.updateSensor(ctx, id, request, time.Time{})
+}
+
+func (c *GreyNoiseClient) updateSensor(ctx context.Context, id string, request SensorUpdateRequest,
+	unmodifiedSince time.Time) error {
 	u := c.baseURL.ResolveReference(&url.URL{Path: fmt.Sprintf("/v1/workspaces/%s/sensors/%s",
 		c.WorkspaceID(), id)})
 
@@ -238,6 +314,9 @@ func (c *GreyNoiseClient) UpdateSensor(ctx context.Context, id string, request S
 		return err
 	}
 
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "PUT", u.String(), bytes.NewBuffer(body))
 	if err != nil {
 		return err
@@ -246,8 +325,12 @@ func (c *GreyNoiseClient) UpdateSensor(ctx context.Context, id string, request S
 	c.setAuthHeader(req)
 	c.setJSONContentHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+	if !unmodifiedSince.IsZero() {
+		req.Header.Set(HeaderIfUnmodifiedSince, unmodifiedSince.Add(time.Second-1).UTC().Format(http.TimeFormat))
+	}
+
+	resp, err := c.do(ctx, id, req, body)
+	if err != nil || resp == nil {
 		return err
 	}
 
@@ -256,12 +339,54 @@ func (c *GreyNoiseClient) UpdateSensor(ctx context.Context, id string, request S
 			return ErrNotFound
 		}
 
+		if resp.StatusCode == http.StatusPreconditionFailed {
+			return fmt.Errorf("%w: sensor %s was updated after %s", ErrConflict, id,
+				unmodifiedSince.Format(time.RFC3339Nano))
+		}
+
 		return NewErrUnexpectedStatusCode(http.StatusAccepted, resp.StatusCode)
 	}
 
 	return nil
 }
 
+// LockSensor locks the sensor for the caller until the returned function is called, waiting for other callers
+// to unlock it. Updates replace the whole sensor, so that concurrent read-modify-write of the same sensor
+// would otherwise lose changes. Different sensors are locked independently.
+func (c *GreyNoiseClient) LockSensor(ctx context.Context, id string) (func(), error) {
+	unlock, err := c.sensorLocks.Lock(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for lock of sensor %s: %w", id, err)
+	}
+
+	return unlock, nil
+}
+
+// WaitForSensor polls the sensor until done returns true for it, as changes accepted by UpdateSensor are
+// applied asynchronously. It returns the sensor matching, or an error once the context is done. The sensor
+// is then considered last modified by this client for ConditionalUpdateSensor. Every update applied is
+// waited for, updates are not sent in dry-run mode so that the sensor is left unmodified.
+func (c *GreyNoiseClient) WaitForSensor(ctx context.Context, id string, done func(*Sensor) bool) (*Sensor, error) {
+	for {
+		sensor, err := c.GetSensor(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if done(sensor) {
+			c.ownUpdates.Store(id, sensor.UpdatedAt)
+
+			return sensor, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for sensor %s: %w", id, ctx.Err())
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
 func (c *GreyNoiseClient) SensorsSearch(ctx context.Context, filters SensorSearchFilter) (*SensorSearchResponse, error) {
 	if filters.SortBy == "" {
 		filters.SortBy = SensorSortByCreatedAt
@@ -291,6 +416,9 @@ func (c *GreyNoiseClient) SensorsSearch(ctx context.Context, filters SensorSearc
 		}
 	}
 
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
@@ -301,7 +429,7 @@ func (c *GreyNoiseClient) SensorsSearch(ctx context.Context, filters SensorSearc
 	c.setAuthHeader(req)
 	c.setJSONContentHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(ctx, "", req, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -321,6 +449,45 @@ func (c *GreyNoiseClient) SensorsSearch(ctx context.Context, filters SensorSearc
 
 	return &result, nil
 }
+
+// GetSensorBootstrapScript downloads the script used to bootstrap a sensor.
+func (c *GreyNoiseClient) GetSensorBootstrapScript(ctx context.Context) ([]byte, error) {
+	return c.getScript(ctx, c.SensorBootstrapURL())
+}
+
+// GetSensorUnBootstrapScript downloads the script used to unbootstrap a sensor.
+func (c *GreyNoiseClient) GetSensorUnBootstrapScript(ctx context.Context) ([]byte, error) {
+	return c.getScript(ctx, c.SensorUnBootstrapURL())
+}
+
+func (c *GreyNoiseClient) getScript(ctx context.Context, u *url.URL) ([]byte, error) {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setAuthHeader(req)
+
+	resp, err := c.do(ctx, "", req, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+
+		return nil, NewErrUnexpectedStatusCode(http.StatusOK, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
 func (c *GreyNoiseClient) SensorBootstrapURL() *url.URL {
 	return c.baseURL.ResolveReference(&url.URL{
 		Path: fmt.Sprintf("/v1/workspaces/%s/sensors/bootstrap/script", c.WorkspaceID()),
@@ -339,6 +506,98 @@ func (c *GreyNoiseClient) SensorsURL() *url.URL {
 	})
 }
 
+// do sends a request. Requests other than GET and HEAD change resources, they are sent through mutate
+// so that read-only, dry-run and the audit log apply to all of them.
+func (c *GreyNoiseClient) do(ctx context.Context, sensorID string, req *http.Request, body []byte) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return c.httpClient.Do(req)
+	}
+
+	return c.mutate(ctx, sensorID, req, body)
+}
+
+// mutate sends a request that changes resources. In read-only mode, ErrReadOnly is returned and in
+// dry-run mode, the request is logged instead and a nil response is returned. Requests are recorded
+// in the audit log, if any, with whether they were sent, refused or only logged.
+func (c *GreyNoiseClient) mutate(ctx context.Context, sensorID string, req *http.Request, body []byte) (*http.Response, error) {
+	entry := AuditEntry{
+		Time:     time.Now().UTC(),
+		Method:   req.Method,
+		Path:     req.URL.Path,
+		SensorID: sensorID,
+		Request:  redactJSON(body),
+	}
+
+	if c.readOnly {
+		tflog.Warn(ctx, "Read-only, request refused", map[string]interface{}{
+			"method":  req.Method,
+			"url":     req.URL.String(),
+			"outcome": AuditOutcomeRefused,
+		})
+
+		entry.Outcome = AuditOutcomeRefused
+		entry.Error = ErrReadOnly.Error()
+		c.audit(ctx, entry)
+
+		return nil, ErrReadOnly
+	}
+
+	if c.dryRun {
+		tflog.Info(ctx, "Dry run, request not sent", map[string]interface{}{
+			"method":  req.Method,
+			"url":     req.URL.String(),
+			"body":    string(body),
+			"outcome": AuditOutcomeDryRun,
+		})
+
+		entry.Outcome = AuditOutcomeDryRun
+		c.audit(ctx, entry)
+
+		return nil, nil
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+
+	entry.Outcome = AuditOutcomeSent
+	entry.DurationMS = time.Since(start).Milliseconds()
+	if resp != nil {
+		entry.Status = resp.StatusCode
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	c.audit(ctx, entry)
+
+	return resp, err
+}
+
+// audit records the entry in the audit log, if any. Failures are logged rather than failing the request,
+// which has already been handled.
+func (c *GreyNoiseClient) audit(ctx context.Context, entry AuditEntry) {
+	if c.auditLog == nil {
+		return
+	}
+
+	if err := c.auditLog.record(entry); err != nil {
+		tflog.Error(ctx, "Error recording request in audit log", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// withDefaultTimeout applies the default timeout to requests, including retries, if the context has no
+// deadline, so that a hung request does not block forever. A deadline of the context, e.g. from the timeouts
+// of a resource, is left as is.
+func withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, defaultRequestTimeout)
+}
+
 func (c *GreyNoiseClient) setAuthHeader(req *http.Request) {
 	req.Header.Set(HeaderKey, c.apiKey)
 }
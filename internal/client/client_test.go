@@ -3,6 +3,7 @@ package client_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -30,6 +31,12 @@ func TestGreyNoiseClient_GetPersona(t *testing.T) {
 		},
 		Description: "A Remote Desktop Protocol server. Designed to observe credential " +
 			"bruteforce activity.",
+		ApplicationProtocols: []string{
+			"rdp",
+		},
+		Ports: []int32{
+			3389,
+		},
 	}
 
 	testAccountJSON := `
@@ -210,6 +217,12 @@ func TestGreyNoiseClient_PersonasSearch(t *testing.T) {
 		},
 		Description: "A Remote Desktop Protocol server. Designed to observe credential " +
 			"bruteforce activity.",
+		ApplicationProtocols: []string{
+			"rdp",
+		},
+		Ports: []int32{
+			3389,
+		},
 	}
 
 	testAccountJSON := `
@@ -310,6 +323,45 @@ func TestGreyNoiseClient_PersonasSearch(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "page",
+			input: client.PersonaSearchFilters{
+				Search: "rdp",
+				Page:   2,
+			},
+			expect: func(t *testing.T, httpClient *client.MockHTTPClient) {
+				httpClient.EXPECT().
+					Do(gomock.Any()).
+					DoAndReturn(func(req *http.Request) (*http.Response, error) {
+						assert.Equal(t, "https://api.greynoise.io/v1/personas?"+
+							"page=2&page_size=100&search=rdp&"+
+							"workspace=25443a54-1e10-45e8-8164-c38aa238615e", req.URL.String())
+
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body: responseBody(`
+								{
+								  "items": [],
+								  "pagination": {
+									"page": 2,
+									"page_size": 100,
+									"total_items": 1
+								  }
+								}`),
+						}, nil
+					})
+			},
+			want: want{
+				response: &client.PersonaSearchResponse{
+					Items: []client.Persona{},
+					Pagination: client.Pagination{
+						Page:       2,
+						PageSize:   100,
+						TotalItems: 1,
+					},
+				},
+			},
+		},
 		{
 			name: "http client error",
 			expect: func(t *testing.T, httpClient *client.MockHTTPClient) {
@@ -610,11 +662,33 @@ func TestGreyNoiseClient_UpdateSensor(t *testing.T) {
 	}
 
 	testCases := []struct {
-		name   string
-		input  input
-		expect func(*testing.T, *client.MockHTTPClient)
-		want   error
+		name    string
+		options []client.Option
+		input   input
+		expect  func(*testing.T, *client.MockHTTPClient)
+		want    error
 	}{
+		{
+			name:    "read-only",
+			options: []client.Option{client.WithReadOnly(true)},
+			input: input{
+				id: "ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+				req: client.SensorUpdateRequest{
+					Persona: "bc65d8a0-ed21-417e-a1a2-65a4e09c3144",
+				},
+			},
+			want: client.ErrReadOnly,
+		},
+		{
+			name:    "dry run",
+			options: []client.Option{client.WithDryRun(true)},
+			input: input{
+				id: "ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+				req: client.SensorUpdateRequest{
+					Persona: "bc65d8a0-ed21-417e-a1a2-65a4e09c3144",
+				},
+			},
+		},
 		{
 			name: "happy path",
 			input: input{
@@ -663,6 +737,30 @@ func TestGreyNoiseClient_UpdateSensor(t *testing.T) {
 					})
 			},
 		},
+		{
+			name: "enable",
+			input: input{
+				id: "ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+				req: client.SensorUpdateRequest{
+					Disabled: new(bool),
+				},
+			},
+			expect: func(t *testing.T, httpClient *client.MockHTTPClient) {
+				httpClient.EXPECT().
+					Do(gomock.Any()).
+					DoAndReturn(func(req *http.Request) (*http.Response, error) {
+						assert.Equal(t, req.Method, http.MethodPut)
+
+						body, err := io.ReadAll(req.Body)
+						assert.NoError(t, err)
+						assert.JSONEq(t, `{"disabled": false}`, string(body))
+
+						return &http.Response{
+							StatusCode: http.StatusAccepted,
+						}, nil
+					})
+			},
+		},
 		{
 			name: "http client error",
 			input: input{
@@ -734,7 +832,8 @@ func TestGreyNoiseClient_UpdateSensor(t *testing.T) {
 				tc.expect(t, mockHTTPClient)
 			}
 
-			gClient, err := client.New(testAPIKey, client.WithHTTPClient(mockHTTPClient))
+			gClient, err := client.New(testAPIKey,
+				append(tc.options, client.WithHTTPClient(mockHTTPClient))...)
 			assert.NoError(t, err)
 
 			err = gClient.UpdateSensor(context.Background(), tc.input.id, tc.input.req)
@@ -958,6 +1057,413 @@ func TestGreyNoiseClient_SensorSearch(t *testing.T) {
 	}
 }
 
+func TestGreyNoiseClient_GetSensorBootstrapScript(t *testing.T) {
+	testAPIKey := "test-5o3uwofjsldfj"
+	testAccountJSON := `
+{
+  "user_id": "8c65d8a0-ed21-417e-a1a2-65a4e09c3144",
+  "workspace_id": "3c65d8a0-ed21-417e-a1a2-65a4e09c3144"
+}`
+	testScript := "#!/usr/bin/env bash\necho bootstrap\n"
+
+	mockAccount := func(t *testing.T, httpClient *client.MockHTTPClient) {
+		httpClient.EXPECT().
+			Do(gomock.Any()).
+			DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, req.Method, http.MethodGet)
+				assert.Equal(t, testAPIKey, req.Header.Get(client.HeaderKey))
+				assert.Equal(t, "https://api.greynoise.io/v1/account", req.URL.String())
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       responseBody(testAccountJSON),
+				}, nil
+			})
+	}
+
+	type want struct {
+		response []byte
+		err      error
+	}
+
+	testCases := []struct {
+		name   string
+		get    func(*client.GreyNoiseClient) ([]byte, error)
+		expect func(*testing.T, *client.MockHTTPClient)
+		want   want
+	}{
+		{
+			name: "happy path - bootstrap",
+			get: func(c *client.GreyNoiseClient) ([]byte, error) {
+				return c.GetSensorBootstrapScript(context.Background())
+			},
+			expect: func(t *testing.T, httpClient *client.MockHTTPClient) {
+				httpClient.EXPECT().
+					Do(gomock.Any()).
+					DoAndReturn(func(req *http.Request) (*http.Response, error) {
+						assert.Equal(t, req.Method, http.MethodGet)
+						assert.Equal(t, testAPIKey, req.Header.Get(client.HeaderKey))
+						assert.Equal(t, "https://api.greynoise.io/v1/workspaces/"+
+							"3c65d8a0-ed21-417e-a1a2-65a4e09c3144/sensors/bootstrap/script", req.URL.String())
+
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       responseBody(testScript),
+						}, nil
+					})
+			},
+			want: want{
+				response: []byte(testScript),
+			},
+		},
+		{
+			name: "happy path - unbootstrap",
+			get: func(c *client.GreyNoiseClient) ([]byte, error) {
+				return c.GetSensorUnBootstrapScript(context.Background())
+			},
+			expect: func(t *testing.T, httpClient *client.MockHTTPClient) {
+				httpClient.EXPECT().
+					Do(gomock.Any()).
+					DoAndReturn(func(req *http.Request) (*http.Response, error) {
+						assert.Equal(t, req.Method, http.MethodGet)
+						assert.Equal(t, testAPIKey, req.Header.Get(client.HeaderKey))
+						assert.Equal(t, "https://api.greynoise.io/v1/workspaces/"+
+							"3c65d8a0-ed21-417e-a1a2-65a4e09c3144/sensors/unbootstrap/script", req.URL.String())
+
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       responseBody(testScript),
+						}, nil
+					})
+			},
+			want: want{
+				response: []byte(testScript),
+			},
+		},
+		{
+			name: "http client error",
+			get: func(c *client.GreyNoiseClient) ([]byte, error) {
+				return c.GetSensorBootstrapScript(context.Background())
+			},
+			expect: func(t *testing.T, httpClient *client.MockHTTPClient) {
+				httpClient.EXPECT().
+					Do(gomock.Any()).
+					Return(nil, errors.New("http error"))
+			},
+			want: want{
+				err: errors.New("http error"),
+			},
+		},
+		{
+			name: "not found",
+			get: func(c *client.GreyNoiseClient) ([]byte, error) {
+				return c.GetSensorUnBootstrapScript(context.Background())
+			},
+			expect: func(t *testing.T, httpClient *client.MockHTTPClient) {
+				httpClient.EXPECT().
+					Do(gomock.Any()).
+					Return(&http.Response{
+						StatusCode: http.StatusNotFound,
+						Body:       responseBody(``),
+					}, nil)
+			},
+			want: want{
+				err: client.ErrNotFound,
+			},
+		},
+		{
+			name: "unexpected status code",
+			get: func(c *client.GreyNoiseClient) ([]byte, error) {
+				return c.GetSensorBootstrapScript(context.Background())
+			},
+			expect: func(t *testing.T, httpClient *client.MockHTTPClient) {
+				httpClient.EXPECT().
+					Do(gomock.Any()).
+					Return(&http.Response{
+						StatusCode: http.StatusForbidden,
+						Body:       responseBody(``),
+					}, nil)
+			},
+			want: want{
+				err: client.NewErrUnexpectedStatusCode(http.StatusOK, http.StatusForbidden),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockHTTPClient := client.NewMockHTTPClient(ctrl)
+			mockAccount(t, mockHTTPClient)
+
+			if tc.expect != nil {
+				tc.expect(t, mockHTTPClient)
+			}
+
+			gClient, err := client.New(testAPIKey, client.WithHTTPClient(mockHTTPClient))
+			assert.NoError(t, err)
+
+			response, err := tc.get(gClient)
+			assert.Equal(t, tc.want.response, response)
+			assert.Equal(t, tc.want.err, err)
+		})
+	}
+}
+
 func responseBody(body string) io.ReadCloser {
 	return io.NopCloser(strings.NewReader(body))
 }
+
+func TestGreyNoiseClient_RequestTimeout(t *testing.T) {
+	testAccountJSON := `
+{
+  "user_id": "4c65d8a0-ed21-417e-a1a2-65a4e09c3144",
+  "workspace_id": "7c65d8a0-ed21-417e-a1a2-65a4e09c3144"
+}`
+
+	testCases := []struct {
+		name    string
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{
+			name: "default",
+			want: 30 * time.Second,
+		},
+		{
+			name:    "context deadline after default",
+			timeout: 20 * time.Minute,
+			want:    20 * time.Minute,
+		},
+		{
+			name:    "context deadline before default",
+			timeout: 10 * time.Second,
+			want:    10 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockHTTPClient := client.NewMockHTTPClient(ctrl)
+
+			mockHTTPClient.EXPECT().
+				Do(gomock.Any()).
+				Return(&http.Response{StatusCode: http.StatusOK, Body: responseBody(testAccountJSON)}, nil)
+
+			gClient, err := client.New("test-7037403284", client.WithHTTPClient(mockHTTPClient))
+			assert.NoError(t, err)
+
+			ctx := context.Background()
+			if tc.timeout != 0 {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			mockHTTPClient.EXPECT().
+				Do(gomock.Any()).
+				DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					deadline, ok := req.Context().Deadline()
+					assert.True(t, ok)
+					assert.WithinDuration(t, time.Now().Add(tc.want), deadline, time.Second)
+
+					return &http.Response{StatusCode: http.StatusAccepted}, nil
+				})
+
+			assert.NoError(t, gClient.UpdateSensor(ctx, "ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+				client.SensorUpdateRequest{Persona: "bc65d8a0-ed21-417e-a1a2-65a4e09c3144"}))
+		})
+	}
+}
+
+func TestGreyNoiseClient_WaitForSensor(t *testing.T) {
+	testAccountJSON := `
+{
+  "user_id": "4c65d8a0-ed21-417e-a1a2-65a4e09c3144",
+  "workspace_id": "7c65d8a0-ed21-417e-a1a2-65a4e09c3144"
+}`
+	sensorJSON := func(persona string) string {
+		return fmt.Sprintf(`{"sensor_id": "ac65d8a0-ed21-417e-a1a2-65a4e09c3144", "persona": %q}`, persona)
+	}
+
+	testCases := []struct {
+		name    string
+		timeout time.Duration
+		polls   []string
+		want    *client.Sensor
+		wantErr error
+	}{
+		{
+			name:  "applied",
+			polls: []string{"a", "a", "b"},
+			want:  &client.Sensor{ID: "ac65d8a0-ed21-417e-a1a2-65a4e09c3144", Persona: "b"},
+		},
+		{
+			name:    "timeout",
+			timeout: 50 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockHTTPClient := client.NewMockHTTPClient(ctrl)
+
+			mockHTTPClient.EXPECT().
+				Do(gomock.Any()).
+				Return(&http.Response{StatusCode: http.StatusOK, Body: responseBody(testAccountJSON)}, nil)
+
+			gClient, err := client.New("test-7037403284",
+				client.WithHTTPClient(mockHTTPClient), client.WithPollInterval(time.Millisecond))
+			assert.NoError(t, err)
+
+			var polls int
+
+			mockHTTPClient.EXPECT().
+				Do(gomock.Any()).
+				DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "https://api.greynoise.io/v1/workspaces/"+
+						"7c65d8a0-ed21-417e-a1a2-65a4e09c3144/sensors/ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+						req.URL.String())
+
+					persona := "a"
+					if polls < len(tc.polls) {
+						persona = tc.polls[polls]
+					}
+					polls++
+
+					return &http.Response{StatusCode: http.StatusOK, Body: responseBody(sensorJSON(persona))}, nil
+				}).
+				AnyTimes()
+
+			ctx := context.Background()
+			if tc.timeout != 0 {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			sensor, err := gClient.WaitForSensor(ctx, "ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+				func(sensor *client.Sensor) bool {
+					return sensor.Persona == "b"
+				})
+
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.want, sensor)
+
+			if tc.wantErr == nil {
+				assert.Equal(t, len(tc.polls), polls)
+			}
+		})
+	}
+}
+
+func TestGreyNoiseClient_ConditionalUpdateSensor(t *testing.T) {
+	testAccountJSON := `
+{
+  "user_id": "4c65d8a0-ed21-417e-a1a2-65a4e09c3144",
+  "workspace_id": "7c65d8a0-ed21-417e-a1a2-65a4e09c3144"
+}`
+	sensorJSON := func(persona, updatedAt string) string {
+		return fmt.Sprintf(`{"sensor_id": "ac65d8a0-ed21-417e-a1a2-65a4e09c3144", "persona": %q, "updated_at": %q}`,
+			persona, updatedAt)
+	}
+	unmodifiedSince := time.Date(2024, 8, 26, 13, 53, 7, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		updatedAt  string
+		ownUpdate  bool
+		putStatus  int
+		wantHeader string
+		want       error
+	}{
+		{
+			name:       "unmodified",
+			updatedAt:  "2024-08-26T13:53:07Z",
+			putStatus:  http.StatusAccepted,
+			wantHeader: "Mon, 26 Aug 2024 13:53:07 GMT",
+		},
+		{
+			name:      "modified",
+			updatedAt: "2024-08-27T09:12:44Z",
+			want:      client.ErrConflict,
+		},
+		{
+			name:       "modified by the client",
+			updatedAt:  "2024-08-27T09:12:44.25Z",
+			ownUpdate:  true,
+			putStatus:  http.StatusAccepted,
+			wantHeader: "Tue, 27 Aug 2024 09:12:45 GMT",
+		},
+		{
+			name:      "modified within the same second",
+			updatedAt: "2024-08-26T13:53:07.25Z",
+			want:      client.ErrConflict,
+		},
+		{
+			name:       "modified before the update",
+			updatedAt:  "2024-08-26T13:53:07Z",
+			putStatus:  http.StatusPreconditionFailed,
+			wantHeader: "Mon, 26 Aug 2024 13:53:07 GMT",
+			want:       client.ErrConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockHTTPClient := client.NewMockHTTPClient(ctrl)
+
+			mockHTTPClient.EXPECT().
+				Do(gomock.Any()).
+				Return(&http.Response{StatusCode: http.StatusOK, Body: responseBody(testAccountJSON)}, nil)
+
+			gClient, err := client.New("test-7037403284", client.WithHTTPClient(mockHTTPClient))
+			assert.NoError(t, err)
+
+			getSensor := mockHTTPClient.EXPECT().
+				Do(gomock.Any()).
+				DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, http.MethodGet, req.Method)
+
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       responseBody(sensorJSON("bc65d8a0-ed21-417e-a1a2-65a4e09c3144", tc.updatedAt)),
+					}, nil
+				})
+
+			if tc.ownUpdate {
+				getSensor.Times(2)
+
+				_, err := gClient.WaitForSensor(context.Background(), "ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+					func(*client.Sensor) bool {
+						return true
+					})
+				assert.NoError(t, err)
+			}
+
+			if tc.putStatus != 0 {
+				mockHTTPClient.EXPECT().
+					Do(gomock.Any()).
+					DoAndReturn(func(req *http.Request) (*http.Response, error) {
+						assert.Equal(t, http.MethodPut, req.Method)
+						assert.Equal(t, tc.wantHeader, req.Header.Get(client.HeaderIfUnmodifiedSince))
+
+						return &http.Response{StatusCode: tc.putStatus, Body: responseBody("")}, nil
+					})
+			}
+
+			err = gClient.ConditionalUpdateSensor(context.Background(), "ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+				client.SensorUpdateRequest{Persona: "cc65d8a0-ed21-417e-a1a2-65a4e09c3144"}, unmodifiedSince)
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+}
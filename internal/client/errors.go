@@ -1,12 +1,21 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
 )
 
 var ErrNotFound = errors.New("not found")
 
+// ErrConflict is returned by conditional updates of resources modified in the meantime.
+var ErrConflict = errors.New("conflict")
+
+// ErrReadOnly is returned for requests that would change resources when the client is read-only.
+var ErrReadOnly = errors.New("client is read-only, request not allowed")
+
 // ErrUnexpectedStatusCode is an error type that is returned when a status code does not match an expected one.
 type ErrUnexpectedStatusCode struct {
 	expected int
@@ -50,3 +59,35 @@ func (e *ErrMissingField) Error() string {
 func NewErrMissingField(field string) *ErrMissingField {
 	return &ErrMissingField{field: field}
 }
+
+// IsNotFound returns whether the error is due to a resource not found.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict returns whether the error is due to a resource modified in the meantime.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsUnauthorized returns whether the error is due to the API key being invalid or lacking permissions.
+func IsUnauthorized(err error) bool {
+	var statusCodeErr *ErrUnexpectedStatusCode
+	if !errors.As(err, &statusCodeErr) {
+		return false
+	}
+
+	return statusCodeErr.actual == http.StatusUnauthorized || statusCodeErr.actual == http.StatusForbidden
+}
+
+// IsTransient returns whether the error is temporary, such that the request may succeed when retried later.
+func IsTransient(err error) bool {
+	var statusCodeErr *ErrUnexpectedStatusCode
+	if errors.As(err, &statusCodeErr) {
+		return statusCodeErr.actual == http.StatusTooManyRequests || statusCodeErr.actual >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
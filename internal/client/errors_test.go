@@ -1,7 +1,9 @@
 package client_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -20,3 +22,63 @@ func TestNewErrUnexpectedStatusCode(t *testing.T) {
 		t.Fatalf("Unable to convert to typed error")
 	}
 }
+
+func TestErrorClassification(t *testing.T) {
+	testCases := []struct {
+		name             string
+		err              error
+		wantNotFound     bool
+		wantConflict     bool
+		wantUnauthorized bool
+		wantTransient    bool
+	}{
+		{
+			name:         "not found",
+			err:          fmt.Errorf("sensor: %w", client.ErrNotFound),
+			wantNotFound: true,
+		},
+		{
+			name:         "conflict",
+			err:          fmt.Errorf("%w: sensor was updated", client.ErrConflict),
+			wantConflict: true,
+		},
+		{
+			name:             "unauthorized",
+			err:              client.NewErrUnexpectedStatusCode(http.StatusOK, http.StatusUnauthorized),
+			wantUnauthorized: true,
+		},
+		{
+			name:             "forbidden",
+			err:              client.NewErrUnexpectedStatusCode(http.StatusOK, http.StatusForbidden),
+			wantUnauthorized: true,
+		},
+		{
+			name:          "too many requests",
+			err:           client.NewErrUnexpectedStatusCode(http.StatusOK, http.StatusTooManyRequests),
+			wantTransient: true,
+		},
+		{
+			name:          "server error",
+			err:           client.NewErrUnexpectedStatusCode(http.StatusAccepted, http.StatusBadGateway),
+			wantTransient: true,
+		},
+		{
+			name:          "deadline exceeded",
+			err:           fmt.Errorf("request: %w", context.DeadlineExceeded),
+			wantTransient: true,
+		},
+		{
+			name: "bad request",
+			err:  client.NewErrUnexpectedStatusCode(http.StatusAccepted, http.StatusBadRequest),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantNotFound, client.IsNotFound(tc.err))
+			assert.Equal(t, tc.wantConflict, client.IsConflict(tc.err))
+			assert.Equal(t, tc.wantUnauthorized, client.IsUnauthorized(tc.err))
+			assert.Equal(t, tc.wantTransient, client.IsTransient(tc.err))
+		})
+	}
+}
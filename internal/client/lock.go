@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// keyedMutex is a set of mutexes by key, allocated while locked or waited for. The zero value is ready to use.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// Lock locks the key, waiting until it is unlocked or the context is done. The returned function unlocks it.
+func (m *keyedMutex) Lock(ctx context.Context, key string) (func(), error) {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = map[string]*keyedLock{}
+	}
+
+	lock, ok := m.locks[key]
+	if !ok {
+		lock = &keyedLock{ch: make(chan struct{}, 1)}
+		m.locks[key] = lock
+	}
+	lock.refs++
+	m.mu.Unlock()
+
+	select {
+	case lock.ch <- struct{}{}:
+		var once sync.Once
+
+		return func() {
+			once.Do(func() {
+				<-lock.ch
+				m.release(key, lock)
+			})
+		}, nil
+	case <-ctx.Done():
+		m.release(key, lock)
+
+		return nil, ctx.Err()
+	}
+}
+
+func (m *keyedMutex) release(key string, lock *keyedLock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(m.locks, key)
+	}
+}
@@ -0,0 +1,105 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+func newLockTestClient(t *testing.T) *client.GreyNoiseClient {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockHTTPClient := client.NewMockHTTPClient(ctrl)
+
+	mockHTTPClient.EXPECT().
+		Do(gomock.Any()).
+		Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body: responseBody(`{
+			  "user_id": "4c65d8a0-ed21-417e-a1a2-65a4e09c3144",
+			  "workspace_id": "7c65d8a0-ed21-417e-a1a2-65a4e09c3144"
+			}`),
+		}, nil)
+
+	gClient, err := client.New("test-7037403284", client.WithHTTPClient(mockHTTPClient))
+	assert.NoError(t, err)
+
+	return gClient
+}
+
+func TestGreyNoiseClient_LockSensor(t *testing.T) {
+	gClient := newLockTestClient(t)
+
+	unlockA, err := gClient.LockSensor(context.Background(), "sensor-a")
+	assert.NoError(t, err)
+
+	// Other sensors are not blocked.
+	unlockB, err := gClient.LockSensor(context.Background(), "sensor-b")
+	assert.NoError(t, err)
+	unlockB()
+
+	// The same sensor is, until unlocked.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = gClient.LockSensor(ctx, "sensor-a")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlockA()
+	unlockA()
+
+	unlockA, err = gClient.LockSensor(context.Background(), "sensor-a")
+	assert.NoError(t, err)
+	unlockA()
+}
+
+// TestGreyNoiseClient_LockSensorRace is meant to be run with -race: counters are read and written
+// without synchronization other than the sensor lock.
+func TestGreyNoiseClient_LockSensorRace(t *testing.T) {
+	const updates = 50
+
+	gClient := newLockTestClient(t)
+
+	counters := map[string]*int{
+		"sensor-a": new(int),
+		"sensor-b": new(int),
+		"sensor-c": new(int),
+	}
+
+	var wg sync.WaitGroup
+
+	for id, counter := range counters {
+		for i := 0; i < updates; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				unlock, err := gClient.LockSensor(context.Background(), id)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer unlock()
+
+				// Read-modify-write, as done by resources updating a sensor.
+				value := *counter
+				time.Sleep(time.Microsecond)
+				*counter = value + 1
+			}()
+		}
+	}
+
+	wg.Wait()
+
+	for id, counter := range counters {
+		assert.Equal(t, updates, *counter, id)
+	}
+}
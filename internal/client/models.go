@@ -57,6 +57,7 @@ type PersonaSearchFilters struct {
 	Categories string `mapstructure:"categories"`
 	Protocols  string `mapstructure:"protocols"`
 	Search     string `mapstructure:"search"`
+	Page       int32  `mapstructure:"page,omitempty"`
 	PageSize   int32  `mapstructure:"page_size"`
 }
 
@@ -83,6 +84,15 @@ type Persona struct {
 	OperatingSystem           string    `json:"operating_system"`
 	Icon                      string    `json:"icon"`
 	AssociatedVulnerabilities []string  `json:"associated_vulnerabilities"`
+	ApplicationProtocols      []string  `json:"application_protocols"`
+	Ports                     []int32   `json:"ports"`
+	// PortProtocols are the transport protocols of Ports, ports without any are TCP.
+	PortProtocols []PersonaPort `json:"port_protocols"`
+}
+
+type PersonaPort struct {
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
 }
 
 type PersonaSearchResponse struct {
@@ -99,6 +109,8 @@ type SensorUpdateRequest struct {
 	Name     string          `json:"name,omitempty"`
 	Persona  string          `json:"persona,omitempty"`
 	Metadata *SensorMetadata `json:"metadata,omitempty"`
+	// Disabled disables or enables the sensor if set, left unchanged otherwise.
+	Disabled *bool `json:"disabled,omitempty"`
 }
 
 type SensorMetadata struct {
@@ -111,6 +123,50 @@ type SensorMetadatum struct {
 	Val    string         `json:"val"`
 }
 
+// Get returns the value of the metadatum with the given name, if any.
+func (m SensorMetadata) Get(name string) (string, bool) {
+	for _, item := range m.Items {
+		if item.Name == name {
+			return item.Val, true
+		}
+	}
+
+	return "", false
+}
+
+// Access returns the access of the metadatum with the given name, if any.
+func (m SensorMetadata) Access(name string) (MetadataAccess, bool) {
+	for _, item := range m.Items {
+		if item.Name == name {
+			return item.Access, true
+		}
+	}
+
+	return "", false
+}
+
+// Set sets the value of the metadatum with the given name, adding it as readwrite if missing.
+// Readonly and hidden metadata are not modified. It returns whether the metadata changed.
+func (m *SensorMetadata) Set(name, val string) bool {
+	for i, item := range m.Items {
+		if item.Name != name {
+			continue
+		}
+
+		if item.Access != MetadataAccessReadWrite || item.Val == val {
+			return false
+		}
+
+		m.Items[i].Val = val
+
+		return true
+	}
+
+	m.Items = append(m.Items, SensorMetadatum{Access: MetadataAccessReadWrite, Name: name, Val: val})
+
+	return true
+}
+
 func (s *SensorMetadatum) Validate() error {
 	switch s.Access {
 	case MetadataAccessHidden, MetadataAccessReadWrite, MetadataAccessReadonly:
@@ -0,0 +1,97 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+func TestSensorMetadata_Set(t *testing.T) {
+	testCases := []struct {
+		name        string
+		metadata    client.SensorMetadata
+		key         string
+		val         string
+		wantChanged bool
+		want        []client.SensorMetadatum
+	}{
+		{
+			name:        "add",
+			key:         "team",
+			val:         "blue",
+			wantChanged: true,
+			want: []client.SensorMetadatum{
+				{Access: client.MetadataAccessReadWrite, Name: "team", Val: "blue"},
+			},
+		},
+		{
+			name: "update",
+			metadata: client.SensorMetadata{Items: []client.SensorMetadatum{
+				{Access: client.MetadataAccessReadWrite, Name: "team", Val: "red"},
+			}},
+			key:         "team",
+			val:         "blue",
+			wantChanged: true,
+			want: []client.SensorMetadatum{
+				{Access: client.MetadataAccessReadWrite, Name: "team", Val: "blue"},
+			},
+		},
+		{
+			name: "unchanged",
+			metadata: client.SensorMetadata{Items: []client.SensorMetadatum{
+				{Access: client.MetadataAccessReadWrite, Name: "team", Val: "blue"},
+			}},
+			key: "team",
+			val: "blue",
+			want: []client.SensorMetadatum{
+				{Access: client.MetadataAccessReadWrite, Name: "team", Val: "blue"},
+			},
+		},
+		{
+			name: "readonly",
+			metadata: client.SensorMetadata{Items: []client.SensorMetadatum{
+				{Access: client.MetadataAccessReadonly, Name: "provider", Val: "greynoise"},
+			}},
+			key: "provider",
+			val: "terraform",
+			want: []client.SensorMetadatum{
+				{Access: client.MetadataAccessReadonly, Name: "provider", Val: "greynoise"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			changed := tc.metadata.Set(tc.key, tc.val)
+
+			assert.Equal(t, tc.wantChanged, changed)
+			assert.Equal(t, tc.want, tc.metadata.Items)
+
+			val, ok := tc.metadata.Get(tc.key)
+			assert.True(t, ok)
+			assert.Equal(t, tc.want[0].Val, val)
+		})
+	}
+}
+
+func TestSensorMetadata_Access(t *testing.T) {
+	metadata := client.SensorMetadata{Items: []client.SensorMetadatum{
+		{Access: client.MetadataAccessReadonly, Name: "provider", Val: "greynoise"},
+		{Access: client.MetadataAccessReadWrite, Name: "team", Val: "blue"},
+	}}
+
+	access, ok := metadata.Access("provider")
+	assert.True(t, ok)
+	assert.Equal(t, client.MetadataAccessReadonly, access)
+
+	access, ok = metadata.Access("team")
+	assert.True(t, ok)
+	assert.Equal(t, client.MetadataAccessReadWrite, access)
+
+	_, ok = metadata.Access("location")
+	assert.False(t, ok)
+}
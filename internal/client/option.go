@@ -2,6 +2,7 @@ package client
 
 import (
 	"net/url"
+	"time"
 )
 
 // Option is used to configure the GreyNoiseClient.
@@ -20,3 +21,31 @@ func WithHTTPClient(httpClient HTTPClient) Option {
 		client.httpClient = httpClient
 	}
 }
+
+// WithReadOnly is used to refuse requests that would change resources.
+func WithReadOnly(readOnly bool) Option {
+	return func(client *GreyNoiseClient) {
+		client.readOnly = readOnly
+	}
+}
+
+// WithDryRun is used to log requests that would change resources instead of sending them.
+func WithDryRun(dryRun bool) Option {
+	return func(client *GreyNoiseClient) {
+		client.dryRun = dryRun
+	}
+}
+
+// WithAuditLogPath is used to record requests that change resources as JSON lines in a file.
+func WithAuditLogPath(path string) Option {
+	return func(client *GreyNoiseClient) {
+		client.auditLog = &auditLog{path: path}
+	}
+}
+
+// WithPollInterval is used to set the interval between requests while waiting for changes to be applied.
+func WithPollInterval(interval time.Duration) Option {
+	return func(client *GreyNoiseClient) {
+		client.pollInterval = interval
+	}
+}
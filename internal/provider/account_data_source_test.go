@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -59,6 +60,63 @@ func TestAccAccountDataSource(t *testing.T) {
 				resource.TestCheckResourceAttr("data.greynoise_account.this", "workspace_id", mockWorkspaceID),
 			),
 		},
+		{
+			name: "success - expected workspace and user",
+			config: fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url              = "%s"
+			  api_key               = "%s"
+			  expected_workspace_id = "%s"
+			  expected_user_id      = "%s"
+			}
+
+			data "greynoise_account" "this" {}
+			`, server.URL, mockAPIKey, strings.ToUpper(mockWorkspaceID), mockUserID),
+			check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("data.greynoise_account.this", "workspace_id", mockWorkspaceID),
+			),
+		},
+		{
+			name: "unexpected workspace",
+			config: fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url              = "%s"
+			  api_key               = "%s"
+			  expected_workspace_id = "7c65d8a0-ed21-417e-a1a2-65a4e09c3144"
+			}
+
+			data "greynoise_account" "this" {}
+			`, server.URL, mockAPIKey),
+			expectError: regexp.MustCompile(fmt.Sprintf(`API key belongs to workspace %s, expected:\s+`+
+				`7c65d8a0-ed21-417e-a1a2-65a4e09c3144`, mockWorkspaceID)),
+		},
+		{
+			name: "unexpected user",
+			config: fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url         = "%s"
+			  api_key          = "%s"
+			  expected_user_id = "4c65d8a0-ed21-417e-a1a2-65a4e09c3144"
+			}
+
+			data "greynoise_account" "this" {}
+			`, server.URL, mockAPIKey),
+			expectError: regexp.MustCompile(fmt.Sprintf(`API key belongs to user %s, expected:\s+`+
+				`4c65d8a0-ed21-417e-a1a2-65a4e09c3144`, mockUserID)),
+		},
+		{
+			name: "invalid expected workspace",
+			config: fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url              = "%s"
+			  api_key               = "%s"
+			  expected_workspace_id = "workspace"
+			}
+
+			data "greynoise_account" "this" {}
+			`, server.URL, mockAPIKey),
+			expectError: regexp.MustCompile(`Invalid expected workspace ID`),
+		},
 		{
 			name: "invalid key",
 			config: fmt.Sprintf(`
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &BootstrapCommandFunction{}
+
+func NewBootstrapCommandFunction() function.Function {
+	return &BootstrapCommandFunction{}
+}
+
+type BootstrapCommandFunction struct{}
+
+func (f *BootstrapCommandFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "bootstrap_command"
+}
+
+func (f *BootstrapCommandFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Command running the bootstrap script",
+		MarkdownDescription: "Returns the command, as run by `bootstrap_script` of `greynoise_sensor_bootstrap`, " +
+			"that runs the bootstrap script downloaded to the temporary file in `$F` with the API key in `$KEY`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "public_ip",
+				MarkdownDescription: "Public IP(s) of the server. Comma-separated list of IPs or CIDRs is acceptable.",
+				Validators: []function.StringParameterValidator{
+					publicIPParameterValidator{},
+				},
+			},
+			function.StringParameter{
+				Name:                "internal_ip",
+				MarkdownDescription: "Internal IP of the server, can be null.",
+				AllowNullValue:      true,
+			},
+			function.Int32Parameter{
+				Name:                "ssh_port",
+				MarkdownDescription: "SSH port to configure after bootstrap. If null, the port returned by the `ssh_port` function is used.",
+				AllowNullValue:      true,
+				Validators: []function.Int32ParameterValidator{
+					sshPortParameterValidator{},
+				},
+			},
+			function.BoolParameter{
+				Name:                "nat",
+				MarkdownDescription: "Whether or not NAT is used to route traffic to the server, can be null.",
+				AllowNullValue:      true,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *BootstrapCommandFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var publicIP, internalIP types.String
+	var sshPort types.Int32
+	var nat types.Bool
+
+	resp.Error = req.Arguments.Get(ctx, &publicIP, &internalIP, &sshPort, &nat)
+	if resp.Error != nil {
+		return
+	}
+
+	publicIPs, err := parsePublicIP(publicIP.ValueString())
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+
+		return
+	}
+
+	port := sshPort.ValueInt32()
+	if sshPort.IsNull() {
+		port = DeterministicSSHPort(publicIPs[0])
+	}
+
+	args := bootstrapArguments(publicIP.ValueString(), internalIP.ValueString(), port, nat.ValueBool())
+
+	resp.Error = resp.Result.Set(ctx, bootstrapCommand("sudo", args))
+}
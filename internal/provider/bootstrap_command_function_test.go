@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestBootstrapCommandFunction(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		args        string
+		command     string
+		expectError *regexp.Regexp
+	}{
+		{
+			name:    "min parameters",
+			args:    `"185.108.182.240", null, null, null`,
+			command: `sudo bash "$F" -k $KEY -p 185.108.182.240 -s 62914`,
+		},
+		{
+			name: "all parameters",
+			args: `"179.108.182.240/32,172.108.182.241/32", "172.108.182.240", 2000, true`,
+			command: `sudo bash "$F" -k $KEY ` +
+				"-p 179.108.182.240/32,172.108.182.241/32 -i 172.108.182.240 -s 2000 -t",
+		},
+		{
+			name:        "invalid IP",
+			args:        `"invalid_ip", null, null, null`,
+			expectError: regexp.MustCompile(`Error occurred while parsing IP:\s+invalid CIDR address: invalid_ip`),
+		},
+		{
+			name:        "invalid SSH port",
+			args:        `"185.108.182.240", null, 70000, null`,
+			expectError: regexp.MustCompile(`SSH port must be between 1 and 65535,\s+got: 70000`),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			step := resource.TestStep{
+				Config: `
+				output "test" {
+				  value = provider::greynoise::bootstrap_command(` + tc.args + `)
+				}`,
+				ExpectError: tc.expectError,
+			}
+
+			if tc.expectError == nil {
+				step.ConfigStateChecks = []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact(tc.command)),
+				}
+			}
+
+			resource.UnitTest(t, resource.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps:                    []resource.TestStep{step},
+			})
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	envVarProfile         = "GN_PROFILE"
+	envVarCredentialsFile = "GN_CREDENTIALS_FILE"
+
+	defaultProfile         = "default"
+	defaultCredentialsFile = "~/.greynoise/credentials"
+	credentialsAPIKey      = "api_key"
+
+	apiKeyCommandTimeout = 30 * time.Second
+)
+
+// resolveAPIKey returns the API key and a description of where it was found, looking in order at
+// the provider configuration, the environment, the credentials file and the API key command.
+// A warning is reported when a different key in the environment is ignored for the one configured.
+func resolveAPIKey(ctx context.Context, config GreyNoiseProviderModel) (string, string, diag.Diagnostics) {
+	if apiKey := config.APIKey.ValueString(); apiKey != "" {
+		var diags diag.Diagnostics
+
+		if envAPIKey := os.Getenv(envVarAPIKey); envAPIKey != "" && envAPIKey != apiKey {
+			diags.AddAttributeWarning(path.Root("api_key"), "GreyNoise API key set more than once",
+				fmt.Sprintf("Using the API key from the provider configuration, the different key set in "+
+					"environment variable %s is ignored.", envVarAPIKey))
+		}
+
+		return apiKey, "provider configuration (api_key)", diags
+	}
+
+	if apiKey := os.Getenv(envVarAPIKey); apiKey != "" {
+		return apiKey, fmt.Sprintf("environment variable %s", envVarAPIKey), nil
+	}
+
+	apiKey, source, diags := apiKeyFromCredentialsFile(config)
+	if diags.HasError() || apiKey != "" {
+		return apiKey, source, diags
+	}
+
+	if !config.APIKeyCommand.IsNull() {
+		var command []string
+
+		diags.Append(config.APIKeyCommand.ElementsAs(ctx, &command, false)...)
+		if diags.HasError() {
+			return "", "", diags
+		}
+
+		apiKey, err := runAPIKeyCommand(ctx, command)
+		if err != nil {
+			diags.AddAttributeError(path.Root("api_key_command"), "Error running API key command",
+				fmt.Sprintf("Error occurred while running %s: %s", command[0], err.Error()))
+
+			return "", "", diags
+		}
+
+		return apiKey, fmt.Sprintf("API key command (%s)", command[0]), diags
+	}
+
+	diags.AddError(
+		"No API key set",
+		fmt.Sprintf("API key must be provided in configuration or set via environment variable: %s. "+
+			"It can otherwise be read from a credentials file (%s by default) or returned by api_key_command.",
+			envVarAPIKey, defaultCredentialsFile),
+	)
+
+	return "", "", diags
+}
+
+// apiKeyFromCredentialsFile returns the API key of the profile in the credentials file. A missing
+// default credentials file or default profile is not an error, as the key might be provided otherwise.
+func apiKeyFromCredentialsFile(config GreyNoiseProviderModel) (string, string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	filename, filenameSet := configOrEnv(config.CredentialsFile.ValueString(), envVarCredentialsFile)
+	if !filenameSet {
+		filename = defaultCredentialsFile
+	}
+
+	profile, profileSet := configOrEnv(config.Profile.ValueString(), envVarProfile)
+	if !profileSet {
+		profile = defaultProfile
+	}
+
+	filename, err := expandHome(filename)
+	if err != nil {
+		diags.AddAttributeError(path.Root("credentials_file"), "Error reading credentials file",
+			fmt.Sprintf("Error occurred while resolving credentials file path: %s", err.Error()))
+
+		return "", "", diags
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !filenameSet && !profileSet {
+			return "", "", nil
+		}
+
+		diags.AddAttributeError(path.Root("credentials_file"), "Error reading credentials file",
+			fmt.Sprintf("Error occurred while reading credentials file: %s", err.Error()))
+
+		return "", "", diags
+	}
+	defer f.Close()
+
+	profiles, err := parseCredentialsFile(f)
+	if err != nil {
+		diags.AddAttributeError(path.Root("credentials_file"), "Error reading credentials file",
+			fmt.Sprintf("Error occurred while parsing credentials file %s: %s", filename, err.Error()))
+
+		return "", "", diags
+	}
+
+	values, ok := profiles[profile]
+	if !ok {
+		if !profileSet {
+			return "", "", nil
+		}
+
+		diags.AddAttributeError(path.Root("profile"), "Profile not found",
+			fmt.Sprintf("Profile %s not found in credentials file %s", profile, filename))
+
+		return "", "", diags
+	}
+
+	if values[credentialsAPIKey] == "" {
+		diags.AddAttributeError(path.Root("profile"), "No API key set",
+			fmt.Sprintf("Profile %s in credentials file %s has no %s", profile, filename, credentialsAPIKey))
+
+		return "", "", diags
+	}
+
+	return values[credentialsAPIKey], fmt.Sprintf("credentials file %s (profile %s)", filename, profile), nil
+}
+
+// parseCredentialsFile parses an INI style credentials file, with a section per profile:
+//
+//	[default]
+//	api_key = ...
+func parseCredentialsFile(r io.Reader) (map[string]map[string]string, error) {
+	profiles := map[string]map[string]string{}
+
+	var profile string
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			profile = strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := profiles[profile]; !ok {
+				profiles[profile] = map[string]string{}
+			}
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected key = value", lineNumber)
+			}
+
+			if profile == "" {
+				return nil, fmt.Errorf("line %d: key outside of a [profile] section", lineNumber)
+			}
+
+			profiles[profile][strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// runAPIKeyCommand runs the command, without a shell, and returns its trimmed output as the API key.
+func runAPIKeyCommand(ctx context.Context, command []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, apiKeyCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() != 0 {
+			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+
+		return "", err
+	}
+
+	apiKey := strings.TrimSpace(stdout.String())
+	if apiKey == "" {
+		return "", errors.New("command returned an empty API key")
+	}
+
+	tflog.Debug(ctx, "API key command completed", map[string]interface{}{
+		"command": command[0],
+	})
+
+	return apiKey, nil
+}
+
+// configOrEnv returns the configured value or else the environment variable, and whether either is set.
+func configOrEnv(value, envVar string) (string, bool) {
+	if value != "" {
+		return value, true
+	}
+
+	value = os.Getenv(envVar)
+
+	return value, value != ""
+}
+
+func expandHome(filename string) (string, error) {
+	if filename != "~" && !strings.HasPrefix(filename, "~/") {
+		return filename, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, filename[1:]), nil
+}
@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCredentialsFile(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		profiles    map[string]map[string]string
+		expectError *regexp.Regexp
+	}{
+		{
+			name: "profiles",
+			content: `
+# GreyNoise credentials
+[default]
+api_key = default-key
+
+[ ci ]
+; used by pipelines
+api_key=ci-key
+`,
+			profiles: map[string]map[string]string{
+				"default": {"api_key": "default-key"},
+				"ci":      {"api_key": "ci-key"},
+			},
+		},
+		{
+			name:        "key outside of profile",
+			content:     "api_key = key\n",
+			expectError: regexp.MustCompile(`line 1: key outside of a \[profile\] section`),
+		},
+		{
+			name:        "invalid line",
+			content:     "[default]\napi_key\n",
+			expectError: regexp.MustCompile(`line 2: expected key = value`),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			profiles, err := parseCredentialsFile(strings.NewReader(tc.content))
+			if tc.expectError != nil {
+				assert.Regexp(t, tc.expectError, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.profiles, profiles)
+		})
+	}
+}
+
+func TestResolveAPIKey(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".greynoise"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".greynoise", "credentials"),
+		[]byte("[default]\napi_key = file-key\n\n[ci]\napi_key = ci-key\n\n[empty]\n"), 0o600))
+
+	otherFile := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, os.WriteFile(otherFile, []byte("[default]\napi_key = other-file-key\n"), 0o600))
+
+	command := func(args ...string) types.List {
+		values := make([]attr.Value, len(args))
+		for i, arg := range args {
+			values[i] = types.StringValue(arg)
+		}
+
+		return types.ListValueMust(types.StringType, values)
+	}
+
+	testCases := []struct {
+		name        string
+		home        string
+		env         map[string]string
+		config      GreyNoiseProviderModel
+		apiKey      string
+		source      string
+		warning     *regexp.Regexp
+		expectError *regexp.Regexp
+	}{
+		{
+			name:    "provider configuration first",
+			home:    home,
+			env:     map[string]string{envVarAPIKey: "env-key"},
+			config:  GreyNoiseProviderModel{APIKey: types.StringValue("config-key")},
+			apiKey:  "config-key",
+			source:  "provider configuration (api_key)",
+			warning: regexp.MustCompile(`different key set in environment variable GN_API_KEY is ignored`),
+		},
+		{
+			name:   "provider configuration same as environment",
+			home:   home,
+			env:    map[string]string{envVarAPIKey: "config-key"},
+			config: GreyNoiseProviderModel{APIKey: types.StringValue("config-key")},
+			apiKey: "config-key",
+			source: "provider configuration (api_key)",
+		},
+		{
+			name:   "environment before credentials file",
+			home:   home,
+			env:    map[string]string{envVarAPIKey: "env-key"},
+			apiKey: "env-key",
+			source: "environment variable GN_API_KEY",
+		},
+		{
+			name:   "default credentials file and profile",
+			home:   home,
+			config: GreyNoiseProviderModel{APIKeyCommand: command("sh", "-c", "echo command-key")},
+			apiKey: "file-key",
+			source: "credentials file " + filepath.Join(home, ".greynoise", "credentials") + " (profile default)",
+		},
+		{
+			name:   "profile",
+			home:   home,
+			config: GreyNoiseProviderModel{Profile: types.StringValue("ci")},
+			apiKey: "ci-key",
+			source: "credentials file " + filepath.Join(home, ".greynoise", "credentials") + " (profile ci)",
+		},
+		{
+			name:   "profile from environment",
+			home:   home,
+			env:    map[string]string{envVarProfile: "ci"},
+			apiKey: "ci-key",
+			source: "credentials file " + filepath.Join(home, ".greynoise", "credentials") + " (profile ci)",
+		},
+		{
+			name:   "credentials file",
+			home:   home,
+			config: GreyNoiseProviderModel{CredentialsFile: types.StringValue(otherFile)},
+			apiKey: "other-file-key",
+			source: "credentials file " + otherFile + " (profile default)",
+		},
+		{
+			name:   "API key command without credentials file",
+			home:   t.TempDir(),
+			config: GreyNoiseProviderModel{APIKeyCommand: command("sh", "-c", "echo ' command-key '")},
+			apiKey: "command-key",
+			source: "API key command (sh)",
+		},
+		{
+			name:        "API key command failure",
+			home:        t.TempDir(),
+			config:      GreyNoiseProviderModel{APIKeyCommand: command("sh", "-c", "echo denied >&2; exit 3")},
+			expectError: regexp.MustCompile(`Error occurred while running sh: exit status 3: denied`),
+		},
+		{
+			name:        "API key command empty output",
+			home:        t.TempDir(),
+			config:      GreyNoiseProviderModel{APIKeyCommand: command("true")},
+			expectError: regexp.MustCompile(`command returned an empty API key`),
+		},
+		{
+			name:        "missing profile",
+			home:        home,
+			config:      GreyNoiseProviderModel{Profile: types.StringValue("prod")},
+			expectError: regexp.MustCompile(`Profile prod not found in credentials file`),
+		},
+		{
+			name:        "profile without API key",
+			home:        home,
+			config:      GreyNoiseProviderModel{Profile: types.StringValue("empty")},
+			expectError: regexp.MustCompile(`Profile empty in credentials file .* has no api_key`),
+		},
+		{
+			name:        "missing credentials file",
+			home:        home,
+			config:      GreyNoiseProviderModel{CredentialsFile: types.StringValue(otherFile + ".missing")},
+			expectError: regexp.MustCompile(`Error occurred while reading credentials file: .*no such file`),
+		},
+		{
+			name:        "no API key",
+			home:        t.TempDir(),
+			expectError: regexp.MustCompile(`API key must be provided in configuration`),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("HOME", tc.home)
+			for _, envVar := range []string{envVarAPIKey, envVarProfile, envVarCredentialsFile} {
+				t.Setenv(envVar, tc.env[envVar])
+			}
+
+			apiKey, source, diags := resolveAPIKey(context.Background(), tc.config)
+			if tc.expectError != nil {
+				require.True(t, diags.HasError())
+				assert.Regexp(t, tc.expectError, diags[0].Detail())
+				return
+			}
+
+			require.False(t, diags.HasError(), diags)
+			assert.Equal(t, tc.apiKey, apiKey)
+			assert.Equal(t, tc.source, source)
+
+			if tc.warning != nil {
+				require.Len(t, diags, 1)
+				assert.Regexp(t, tc.warning, diags[0].Detail())
+			} else {
+				assert.Empty(t, diags)
+			}
+		})
+	}
+}
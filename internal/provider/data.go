@@ -7,4 +7,10 @@ import (
 type Data struct {
 	Client *client.GreyNoiseClient
 	APIKey string
+
+	// DefaultMetadata is merged into the metadata of every sensor updated.
+	DefaultMetadata map[string]string
+
+	// PersonaPolicy restricts the personas applied to sensors.
+	PersonaPolicy personaPolicy
 }
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+// addOperationError adds an error diagnostic for an error while performing an operation, distinguishing
+// the causes a user can act upon.
+func addOperationError(diags *diag.Diagnostics, operation string, err error) {
+	switch {
+	case client.IsNotFound(err):
+		diags.AddError("Not found",
+			fmt.Sprintf("Error occurred while %s: %s. It may have been removed outside of Terraform.",
+				operation, err.Error()))
+	case client.IsConflict(err):
+		diags.AddError("Conflict",
+			fmt.Sprintf("Error occurred while %s: %s. It was modified outside of Terraform since last read, "+
+				"run terraform plan to review the changes before applying again.", operation, err.Error()))
+	case client.IsUnauthorized(err):
+		diags.AddError("Unauthorized",
+			fmt.Sprintf("Error occurred while %s: %s. Check that the API key is valid and allowed to do so.",
+				operation, err.Error()))
+	case client.IsTransient(err):
+		diags.AddError("Transient error",
+			fmt.Sprintf("Error occurred while %s: %s. The GreyNoise API may be unavailable, try again later.",
+				operation, err.Error()))
+	default:
+		diags.AddError("Operation error",
+			fmt.Sprintf("Error occurred while %s: %s", operation, err.Error()))
+	}
+}
+
+// removeNotFoundSensor removes the resource from the state with a warning if the sensor was not found,
+// so that it is planned for creation again rather than failing every plan.
+func removeNotFoundSensor(ctx context.Context, resp *resource.ReadResponse, sensorID string, err error) bool {
+	if !client.IsNotFound(err) {
+		return false
+	}
+
+	resp.Diagnostics.AddWarning("Sensor not found",
+		fmt.Sprintf("Sensor %s was not found, it may have been deregistered outside of Terraform. "+
+			"Removing it from the state.", sensorID))
+	resp.State.RemoveResource(ctx)
+
+	return true
+}
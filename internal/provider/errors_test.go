@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	tfresource "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+func TestAccRemoveNotFoundSensor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		sensorID    string
+		newResource func() resource.Resource
+		config      string
+	}{
+		{
+			name:        "sensor persona",
+			sensorID:    "7d6aed11-f2de-48f9-9526-8fb72be10700",
+			newResource: NewSensorPersonaResource,
+			config: `
+				resource "greynoise_sensor_persona" "this" {
+				  sensor_id  = "7d6aed11-f2de-48f9-9526-8fb72be10700"
+				  persona_id = "501c5e5a-cf2e-4401-844a-04d4391b1332"
+				}`,
+		},
+		{
+			name:        "sensor metadata",
+			sensorID:    "8d6aed11-f2de-48f9-9526-8fb72be10700",
+			newResource: NewSensorMetadataResource,
+			config: `
+				resource "greynoise_sensor_metadata" "this" {
+				  sensor_id = "8d6aed11-f2de-48f9-9526-8fb72be10700"
+				  name      = "Lost Badger"
+				}`,
+		},
+		{
+			name:        "sensor state",
+			sensorID:    "6f6aed11-f2de-48f9-9526-8fb72be10700",
+			newResource: NewSensorStateResource,
+			config: `
+				resource "greynoise_sensor_state" "this" {
+				  sensor_id = "6f6aed11-f2de-48f9-9526-8fb72be10700"
+				  disabled  = false
+				}`,
+		},
+		{
+			name:        "sensor persona rotation",
+			sensorID:    "7f6aed11-f2de-48f9-9526-8fb72be10700",
+			newResource: NewSensorPersonaRotationResource,
+			config: `
+				resource "greynoise_sensor_persona_rotation" "this" {
+				  sensor_id       = "7f6aed11-f2de-48f9-9526-8fb72be10700"
+				  persona_ids     = ["501c5e5a-cf2e-4401-844a-04d4391b1332"]
+				  rotation_period = "168h"
+				  start_time      = "2024-08-01T00:00:00Z"
+				}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			testSensor := &client.Sensor{
+				ID:   tc.sensorID,
+				Name: "Lost Badger",
+				PublicIps: []string{
+					"159.223.200.224",
+				},
+				Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+				Status:    "healthy",
+				Disabled:  false,
+				LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+				CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+			}
+
+			mockServer := defaultMockAPIServer()
+			mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+			mockAPIKey := mockServer.APIKey
+
+			var deregistered atomic.Bool
+
+			mockServer.Register(http.MethodPut,
+				fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+				http.StatusAccepted,
+				emptyBody,
+				nil,
+			)
+			mockServer.RegisterMatch(http.MethodGet,
+				fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+				func(_ *url.URL) bool {
+					return !deregistered.Load()
+				},
+				http.StatusOK,
+				body(testSensor),
+				nil,
+			)
+
+			server := mockServer.Server()
+
+			config := fmt.Sprintf(`
+				provider "greynoise" {
+				  base_url = "%s"
+				  api_key  = "%s"
+				}
+				`, server.URL, mockAPIKey) + tc.config
+
+			tfresource.Test(t, tfresource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []tfresource.TestStep{
+					{
+						Config: config,
+					},
+					{
+						// The sensor is removed from the state and planned for creation again, rather than failing.
+						PreConfig: func() {
+							deregistered.Store(true)
+						},
+						Config:             config,
+						PlanOnly:           true,
+						ExpectNonEmptyPlan: true,
+					},
+				},
+			})
+
+			// The warning is checked reading the resource directly, as acceptance tests do not report warnings.
+			resp := readResource(t, tc.newResource(), server.URL, testSensor.ID)
+			assert.True(t, resp.State.Raw.IsNull())
+			assert.Equal(t, diag.Diagnostics{
+				diag.NewWarningDiagnostic("Sensor not found",
+					fmt.Sprintf("Sensor %s was not found, it may have been deregistered outside of Terraform. "+
+						"Removing it from the state.", testSensor.ID)),
+			}, resp.Diagnostics)
+		})
+	}
+}
+
+// readResource reads a sensor resource with only sensor_id set in its state, from the API at baseURL.
+func readResource(t *testing.T, r resource.Resource, baseURL, sensorID string) *resource.ReadResponse {
+	ctx := context.Background()
+
+	configurable, ok := r.(resource.ResourceWithConfigure)
+	require.True(t, ok)
+	configurable.Configure(ctx, resource.ConfigureRequest{ProviderData: &Data{Client: testClient(t, baseURL)}},
+		&resource.ConfigureResponse{})
+
+	var schemaResp resource.SchemaResponse
+
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError())
+
+	objectType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	require.True(t, ok)
+
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attrType, nil)
+	}
+	values["sensor_id"] = tftypes.NewValue(tftypes.String, sensorID)
+
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, values)}
+	resp := &resource.ReadResponse{State: state}
+
+	r.Read(ctx, resource.ReadRequest{State: state}, resp)
+
+	return resp
+}
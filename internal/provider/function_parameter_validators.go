@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.StringParameterValidator = publicIPParameterValidator{}
+var _ function.Int32ParameterValidator = sshPortParameterValidator{}
+var _ function.ListParameterValidator = portsParameterValidator{}
+
+// publicIPParameterValidator validates a comma-separated list of IPs or CIDRs, as accepted by public_ip.
+type publicIPParameterValidator struct{}
+
+func (v publicIPParameterValidator) ValidateParameterString(_ context.Context,
+	req function.StringParameterValidatorRequest, resp *function.StringParameterValidatorResponse) {
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+
+	if _, err := parsePublicIP(req.Value.ValueString()); err != nil {
+		resp.Error = function.NewArgumentFuncError(req.ArgumentPosition,
+			fmt.Sprintf("Error occurred while parsing IP: %s", err.Error()))
+	}
+}
+
+// sshPortParameterValidator validates a TCP port.
+type sshPortParameterValidator struct{}
+
+func (v sshPortParameterValidator) ValidateParameterInt32(_ context.Context,
+	req function.Int32ParameterValidatorRequest, resp *function.Int32ParameterValidatorResponse) {
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+
+	if port := req.Value.ValueInt32(); port < 1 || port > 65535 {
+		resp.Error = function.NewArgumentFuncError(req.ArgumentPosition,
+			fmt.Sprintf("SSH port must be between 1 and 65535, got: %d", port))
+	}
+}
+
+// portsParameterValidator validates a list of TCP ports.
+type portsParameterValidator struct{}
+
+func (v portsParameterValidator) ValidateParameterList(_ context.Context,
+	req function.ListParameterValidatorRequest, resp *function.ListParameterValidatorResponse) {
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+
+	for _, element := range req.Value.Elements() {
+		port, ok := element.(types.Int32)
+		if !ok || port.IsUnknown() {
+			continue
+		}
+
+		if port.IsNull() || port.ValueInt32() < 1 || port.ValueInt32() > 65535 {
+			resp.Error = function.NewArgumentFuncError(req.ArgumentPosition,
+				fmt.Sprintf("Ports must be between 1 and 65535, got: %s", port.String()))
+
+			return
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &IngressRulesFunction{}
+
+func NewIngressRulesFunction() function.Function {
+	return &IngressRulesFunction{}
+}
+
+type IngressRulesFunction struct{}
+
+func (f *IngressRulesFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "ingress_rules"
+}
+
+func (f *IngressRulesFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Ingress rules opening persona ports",
+		MarkdownDescription: "Returns the ingress rules opening the ports, with consecutive ports merged " +
+			"into a single `{protocol, from_port, to_port}` rule. As functions cannot call the GreyNoise API, " +
+			"the ports of a persona are looked up with the `greynoise_persona_ingress_rules` data source; " +
+			"this function allows to combine the ports of several personas into a single set of rules. " +
+			"Ports are opened as `tcp`, use `rules` of the data source for personas listening on `udp` ports.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:                "ports",
+				ElementType:         types.Int32Type,
+				MarkdownDescription: "Ports to open, e.g. `ports` of `greynoise_persona_ingress_rules`.",
+				Validators: []function.ListParameterValidator{
+					portsParameterValidator{},
+				},
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.ObjectType{
+				AttrTypes: ingressRuleAttrTypes,
+			},
+		},
+	}
+}
+
+func (f *IngressRulesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ports []int32
+
+	resp.Error = req.Arguments.Get(ctx, &ports)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, ingressRules(map[string][]int32{defaultIngressRuleProtocol: ports}))
+}
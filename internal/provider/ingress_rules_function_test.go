@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestIngressRulesFunction(t *testing.T) {
+	t.Parallel()
+
+	rule := func(from, to int64) knownvalue.Check {
+		return knownvalue.ObjectExact(map[string]knownvalue.Check{
+			"protocol":  knownvalue.StringExact("tcp"),
+			"from_port": knownvalue.Int64Exact(from),
+			"to_port":   knownvalue.Int64Exact(to),
+		})
+	}
+
+	testCases := []struct {
+		name        string
+		ports       string
+		rules       []knownvalue.Check
+		expectError *regexp.Regexp
+	}{
+		{
+			name:  "no ports",
+			ports: `[]`,
+			rules: []knownvalue.Check{},
+		},
+		{
+			name:  "ports of several personas",
+			ports: `concat([3389], [8443, 8080, 8081, 8082])`,
+			rules: []knownvalue.Check{
+				rule(3389, 3389),
+				rule(8080, 8082),
+				rule(8443, 8443),
+			},
+		},
+		{
+			name:        "invalid port",
+			ports:       `[22, 0]`,
+			expectError: regexp.MustCompile(`Ports must be between 1 and 65535, got:\s+0`),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			step := resource.TestStep{
+				Config: `
+				output "test" {
+				  value = provider::greynoise::ingress_rules(` + tc.ports + `)
+				}`,
+				ExpectError: tc.expectError,
+			}
+
+			if tc.expectError == nil {
+				step.ConfigStateChecks = []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.ListExact(tc.rules)),
+				}
+			}
+
+			resource.UnitTest(t, resource.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps:                    []resource.TestStep{step},
+			})
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &ParseIPsFunction{}
+
+func NewParseIPsFunction() function.Function {
+	return &ParseIPsFunction{}
+}
+
+type ParseIPsFunction struct{}
+
+func (f *ParseIPsFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_ips"
+}
+
+func (f *ParseIPsFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Parse public IP(s) of a server",
+		MarkdownDescription: "Parses a comma-separated list of IPs or CIDRs into the list of IPs, " +
+			"same as `sensor_public_ips` of `greynoise_sensor_bootstrap`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "public_ip",
+				MarkdownDescription: "Comma-separated list of IPs or CIDRs.",
+				Validators: []function.StringParameterValidator{
+					publicIPParameterValidator{},
+				},
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (f *ParseIPsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var publicIP string
+
+	resp.Error = req.Arguments.Get(ctx, &publicIP)
+	if resp.Error != nil {
+		return
+	}
+
+	publicIPs, err := parsePublicIP(publicIP)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+
+		return
+	}
+
+	ips := make([]string, len(publicIPs))
+	for i, ip := range publicIPs {
+		ips[i] = ip.String()
+	}
+
+	resp.Error = resp.Result.Set(ctx, ips)
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestParseIPsFunction(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		publicIP    string
+		ips         []knownvalue.Check
+		expectError *regexp.Regexp
+	}{
+		{
+			name:     "single IP",
+			publicIP: "185.108.182.240",
+			ips: []knownvalue.Check{
+				knownvalue.StringExact("185.108.182.240"),
+			},
+		},
+		{
+			name:     "IPs and CIDRs",
+			publicIP: "179.108.182.240,186.249.111.211/16,2001:db8::1",
+			ips: []knownvalue.Check{
+				knownvalue.StringExact("179.108.182.240"),
+				knownvalue.StringExact("186.249.111.211"),
+				knownvalue.StringExact("2001:db8::1"),
+			},
+		},
+		{
+			name:        "invalid IP",
+			publicIP:    "185.108.182.240,invalid_ip",
+			expectError: regexp.MustCompile(`Error occurred while parsing IP:\s+invalid CIDR address: invalid_ip`),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			step := resource.TestStep{
+				Config: `
+				output "test" {
+				  value = provider::greynoise::parse_ips("` + tc.publicIP + `")
+				}`,
+				ExpectError: tc.expectError,
+			}
+
+			if tc.expectError == nil {
+				step.ConfigStateChecks = []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.ListExact(tc.ips)),
+				}
+			}
+
+			resource.UnitTest(t, resource.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps:                    []resource.TestStep{step},
+			})
+		})
+	}
+}
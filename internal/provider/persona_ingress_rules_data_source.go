@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+// defaultIngressRuleProtocol is the transport protocol of persona ports without any.
+const defaultIngressRuleProtocol = "tcp"
+
+var _ datasource.DataSource = &PersonaIngressRulesDataSource{}
+
+var ingressRuleAttrTypes = map[string]attr.Type{
+	"protocol":  types.StringType,
+	"from_port": types.Int32Type,
+	"to_port":   types.Int32Type,
+}
+
+func NewPersonaIngressRulesDataSource() datasource.DataSource {
+	return &PersonaIngressRulesDataSource{}
+}
+
+type PersonaIngressRulesDataSource struct {
+	data *Data
+}
+
+type PersonaIngressRulesDataSourceModel struct {
+	PersonaID            types.String       `tfsdk:"persona_id"`
+	ApplicationProtocols types.List         `tfsdk:"application_protocols"`
+	Ports                types.List         `tfsdk:"ports"`
+	Rules                []IngressRuleModel `tfsdk:"rules"`
+}
+
+type IngressRuleModel struct {
+	Protocol types.String `tfsdk:"protocol"`
+	FromPort types.Int32  `tfsdk:"from_port"`
+	ToPort   types.Int32  `tfsdk:"to_port"`
+}
+
+func (d *PersonaIngressRulesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_persona_ingress_rules"
+}
+
+func (d *PersonaIngressRulesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Persona ingress rules data source is used to lookup the ports a persona listens on, as firewall rules. ` +
+			`It allows security groups of sensors to only open the ports of their persona.`,
+		Attributes: map[string]schema.Attribute{
+			"persona_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the persona.",
+				Required:            true,
+			},
+			"application_protocols": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Application protocols of the persona.",
+				Computed:            true,
+			},
+			"ports": schema.ListAttribute{
+				ElementType:         types.Int32Type,
+				MarkdownDescription: "Ports the persona listens on.",
+				Computed:            true,
+			},
+			"rules": schema.ListNestedAttribute{
+				MarkdownDescription: "Ingress rules opening the ports of the persona, by transport protocol, " +
+					"with consecutive ports merged into a single rule.",
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"protocol": schema.StringAttribute{
+							MarkdownDescription: fmt.Sprintf("Transport protocol of the ports, e.g. `%s` or `udp`.",
+								defaultIngressRuleProtocol),
+							Computed: true,
+						},
+						"from_port": schema.Int32Attribute{
+							MarkdownDescription: "Start of the port range.",
+							Computed:            true,
+						},
+						"to_port": schema.Int32Attribute{
+							MarkdownDescription: "End of the port range (inclusive).",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *PersonaIngressRulesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*Data)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("expected *Data, got: %T. "+
+				"Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.data = data
+}
+
+func (d *PersonaIngressRulesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PersonaIngressRulesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	persona, err := d.data.Client.GetPersona(ctx, data.PersonaID.ValueString())
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			resp.Diagnostics.AddError(
+				"Persona error",
+				fmt.Sprintf("Persona not found: %s", data.PersonaID.ValueString()),
+			)
+
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Persona error",
+			fmt.Sprintf("Error occurred while retrieving persona: %s", err.Error()),
+		)
+
+		return
+	}
+
+	applicationProtocols, diags := types.ListValueFrom(ctx, types.StringType, persona.ApplicationProtocols)
+	resp.Diagnostics.Append(diags...)
+	data.ApplicationProtocols = applicationProtocols
+
+	ports, diags := types.ListValueFrom(ctx, types.Int32Type, persona.Ports)
+	resp.Diagnostics.Append(diags...)
+	data.Ports = ports
+
+	data.Rules = ingressRules(personaPortProtocols(persona))
+
+	tflog.Trace(ctx, "Read persona ingress rules data source")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// personaPortProtocols returns the ports of the persona by transport protocol. Ports the persona does not
+// report a transport protocol for are TCP.
+func personaPortProtocols(persona *client.Persona) map[string][]int32 {
+	portProtocols := map[string][]int32{}
+	withProtocol := map[int32]bool{}
+
+	for _, port := range persona.PortProtocols {
+		protocol := strings.ToLower(port.Protocol)
+		portProtocols[protocol] = append(portProtocols[protocol], port.Port)
+		withProtocol[port.Port] = true
+	}
+
+	for _, port := range persona.Ports {
+		if !withProtocol[port] {
+			portProtocols[defaultIngressRuleProtocol] = append(portProtocols[defaultIngressRuleProtocol], port)
+		}
+	}
+
+	return portProtocols
+}
+
+// ingressRules returns the rules opening the ports of each transport protocol, merging consecutive ports
+// into ranges. Rules are sorted by protocol and port.
+func ingressRules(portProtocols map[string][]int32) []IngressRuleModel {
+	rules := []IngressRuleModel{}
+
+	for _, protocol := range sortedKeys(portProtocols) {
+		sorted := slices.Clone(portProtocols[protocol])
+		slices.Sort(sorted)
+		sorted = slices.Compact(sorted)
+
+		for i := 0; i < len(sorted); {
+			j := i
+			for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+				j++
+			}
+
+			rules = append(rules, IngressRuleModel{
+				Protocol: types.StringValue(protocol),
+				FromPort: types.Int32Value(sorted[i]),
+				ToPort:   types.Int32Value(sorted[j]),
+			})
+
+			i = j + 1
+		}
+	}
+
+	return rules
+}
@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+func TestIngressRules(t *testing.T) {
+	testCases := []struct {
+		name  string
+		ports []int32
+		rules [][2]int32
+	}{
+		{
+			name:  "no ports",
+			ports: nil,
+			rules: [][2]int32{},
+		},
+		{
+			name:  "single port",
+			ports: []int32{3389},
+			rules: [][2]int32{{3389, 3389}},
+		},
+		{
+			name:  "unsorted ports with ranges and duplicates",
+			ports: []int32{8443, 80, 8080, 443, 8081, 8082, 80},
+			rules: [][2]int32{{80, 80}, {443, 443}, {8080, 8082}, {8443, 8443}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			rules := ingressRules(map[string][]int32{defaultIngressRuleProtocol: tc.ports})
+
+			ranges := make([][2]int32, len(rules))
+			for i, rule := range rules {
+				assert.Equal(t, defaultIngressRuleProtocol, rule.Protocol.ValueString())
+				ranges[i] = [2]int32{rule.FromPort.ValueInt32(), rule.ToPort.ValueInt32()}
+			}
+
+			assert.Equal(t, tc.rules, ranges)
+		})
+	}
+}
+
+func TestPersonaPortProtocols(t *testing.T) {
+	portProtocols := personaPortProtocols(&client.Persona{
+		Ports: []int32{53, 80, 161, 443},
+		PortProtocols: []client.PersonaPort{
+			{Port: 53, Protocol: "UDP"},
+			{Port: 53, Protocol: "tcp"},
+			{Port: 161, Protocol: "udp"},
+		},
+	})
+
+	assert.Equal(t, map[string][]int32{
+		"tcp": {53, 80, 443},
+		"udp": {53, 161},
+	}, portProtocols)
+}
+
+func TestAccPersonaIngressRulesDataSource(t *testing.T) {
+	t.Parallel()
+
+	mockServer := defaultMockAPIServer()
+	mockServer.Register(http.MethodGet, "/v1/personas/ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+		http.StatusOK,
+		body(client.Persona{
+			ID:                   "ac65d8a0-ed21-417e-a1a2-65a4e09c3144",
+			Name:                 "Apache Tomcat",
+			Tier:                 "premium",
+			ApplicationProtocols: []string{"http", "https"},
+			Ports:                []int32{8443, 8080, 8081, 8082},
+		}),
+		nil,
+	)
+	mockServer.Register(http.MethodGet, "/v1/personas/cc65d8a0-ed21-417e-a1a2-65a4e09c3144",
+		http.StatusOK,
+		body(client.Persona{
+			ID:                   "cc65d8a0-ed21-417e-a1a2-65a4e09c3144",
+			Name:                 "Bind DNS",
+			Tier:                 "premium",
+			ApplicationProtocols: []string{"dns"},
+			Ports:                []int32{53},
+			PortProtocols: []client.PersonaPort{
+				{Port: 53, Protocol: "udp"},
+				{Port: 53, Protocol: "tcp"},
+			},
+		}),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	testCases := []struct {
+		name        string
+		config      string
+		check       resource.TestCheckFunc
+		expectError *regexp.Regexp
+	}{
+		{
+			name: "happy path",
+			config: `
+			data "greynoise_persona_ingress_rules" "this" {
+			  persona_id = "ac65d8a0-ed21-417e-a1a2-65a4e09c3144"
+			}`,
+			check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this",
+					"application_protocols.#", "2"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "ports.#", "4"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "ports.0", "8443"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.#", "2"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.0.protocol", "tcp"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.0.from_port", "8080"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.0.to_port", "8082"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.1.from_port", "8443"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.1.to_port", "8443"),
+			),
+		},
+		{
+			name: "UDP persona",
+			config: `
+			data "greynoise_persona_ingress_rules" "this" {
+			  persona_id = "cc65d8a0-ed21-417e-a1a2-65a4e09c3144"
+			}`,
+			check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.#", "2"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.0.protocol", "tcp"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.0.from_port", "53"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.1.protocol", "udp"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.1.from_port", "53"),
+				resource.TestCheckResourceAttr("data.greynoise_persona_ingress_rules.this", "rules.1.to_port", "53"),
+			),
+		},
+		{
+			name: "persona not found",
+			config: `
+			data "greynoise_persona_ingress_rules" "this" {
+			  persona_id = "bc65d8a0-ed21-417e-a1a2-65a4e09c3144"
+			}`,
+			expectError: regexp.MustCompile(`Persona not found: bc65d8a0-ed21-417e-a1a2-65a4e09c3144`),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(`
+						provider "greynoise" {
+						  base_url = "%s"
+						  api_key  = "%s"
+						}
+						`, server.URL, mockServer.APIKey) + tc.config,
+						Check:       tc.check,
+						ExpectError: tc.expectError,
+					},
+				},
+			})
+		})
+	}
+}
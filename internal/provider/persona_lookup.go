@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+// lookupPersonaByName returns the persona with the name, ignoring case. Unlike search results, it fails
+// unless exactly one persona has the name, so that the resolved persona does not depend on result order.
+// All the pages of the search are compared, as the search also matches personas with other names.
+func lookupPersonaByName(ctx context.Context, c *client.GreyNoiseClient, name string) (*client.Persona, error) {
+	var (
+		matches []client.Persona
+		seen    int
+	)
+
+	for page := int32(0); ; page++ {
+		result, err := c.PersonasSearch(ctx, client.PersonaSearchFilters{
+			Search: name,
+			Page:   page,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error searching personas: %w", err)
+		}
+
+		for _, persona := range result.Items {
+			if strings.EqualFold(persona.Name, name) {
+				matches = append(matches, persona)
+			}
+		}
+
+		seen += len(result.Items)
+
+		if len(result.Items) == 0 || seen >= int(result.Pagination.TotalItems) {
+			return singlePersona(matches, fmt.Sprintf("named %q", name))
+		}
+	}
+}
+
+// lookupPersonaBySearch returns the only persona matching the filters.
+func lookupPersonaBySearch(ctx context.Context, c *client.GreyNoiseClient,
+	filters client.PersonaSearchFilters) (*client.Persona, error) {
+	result, err := c.PersonasSearch(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("error searching personas: %w", err)
+	}
+
+	if int(result.Pagination.TotalItems) > len(result.Items) {
+		return nil, fmt.Errorf("%d personas match the search, expected exactly one", result.Pagination.TotalItems)
+	}
+
+	return singlePersona(result.Items, "matching the search")
+}
+
+func singlePersona(personas []client.Persona, description string) (*client.Persona, error) {
+	switch len(personas) {
+	case 0:
+		return nil, fmt.Errorf("no persona %s", description)
+	case 1:
+		return &personas[0], nil
+	}
+
+	matches := make([]string, len(personas))
+	for i, persona := range personas {
+		matches[i] = fmt.Sprintf("%s (%s)", persona.ID, persona.Name)
+	}
+
+	return nil, fmt.Errorf("%d personas %s, expected exactly one: %s", len(personas), description,
+		strings.Join(matches, ", "))
+}
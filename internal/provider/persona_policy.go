@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+// personaPolicy restricts the personas that can be applied to sensors.
+type personaPolicy struct {
+	AllowedPersonaIDs []string
+	AllowedTiers      []string
+	DeniedCategories  []string
+}
+
+// IsEmpty returns whether the policy allows any persona.
+func (p personaPolicy) IsEmpty() bool {
+	return len(p.AllowedPersonaIDs) == 0 && len(p.AllowedTiers) == 0 && len(p.DeniedCategories) == 0
+}
+
+// Check returns an error describing why the persona is not allowed, if it is not.
+func (p personaPolicy) Check(persona *client.Persona) error {
+	if len(p.AllowedPersonaIDs) != 0 && !slices.Contains(p.AllowedPersonaIDs, persona.ID) {
+		return fmt.Errorf("persona %s (%s) is not in allowed_persona_ids", persona.ID, persona.Name)
+	}
+
+	if len(p.AllowedTiers) != 0 && !containsFold(p.AllowedTiers, persona.Tier) {
+		return fmt.Errorf("persona %s (%s) is of tier %q, allowed tiers: %s",
+			persona.ID, persona.Name, persona.Tier, strings.Join(p.AllowedTiers, ", "))
+	}
+
+	for _, category := range persona.Categories {
+		if containsFold(p.DeniedCategories, category) {
+			return fmt.Errorf("persona %s (%s) is of denied category %q", persona.ID, persona.Name, category)
+		}
+	}
+
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
+
+// checkPersona returns an error if the persona is not allowed by the provider persona restrictions.
+func checkPersona(ctx context.Context, d *Data, personaID string) error {
+	if d.PersonaPolicy.IsEmpty() {
+		return nil
+	}
+
+	persona, err := d.Client.GetPersona(ctx, personaID)
+	if err != nil {
+		return fmt.Errorf("error getting persona %s: %w", personaID, err)
+	}
+
+	return d.PersonaPolicy.Check(persona)
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+func TestPersonaPolicy_Check(t *testing.T) {
+	t.Parallel()
+
+	persona := &client.Persona{
+		ID:         "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Name:       "Cisco ASA",
+		Tier:       "Enterprise",
+		Categories: []string{"vpn", "high-interaction"},
+	}
+
+	testCases := []struct {
+		name    string
+		policy  personaPolicy
+		wantErr string
+	}{
+		{
+			name: "no restrictions",
+		},
+		{
+			name: "allowed",
+			policy: personaPolicy{
+				AllowedPersonaIDs: []string{"501c5e5a-cf2e-4401-844a-04d4391b1332"},
+				AllowedTiers:      []string{"enterprise"},
+				DeniedCategories:  []string{"ics"},
+			},
+		},
+		{
+			name: "persona not allowed",
+			policy: personaPolicy{
+				AllowedPersonaIDs: []string{"601c5e5a-cf2e-4401-844a-04d4391b1332"},
+			},
+			wantErr: "persona 501c5e5a-cf2e-4401-844a-04d4391b1332 (Cisco ASA) is not in allowed_persona_ids",
+		},
+		{
+			name: "tier not allowed",
+			policy: personaPolicy{
+				AllowedTiers: []string{"community"},
+			},
+			wantErr: `persona 501c5e5a-cf2e-4401-844a-04d4391b1332 (Cisco ASA) is of tier "Enterprise", ` +
+				`allowed tiers: community`,
+		},
+		{
+			name: "category denied",
+			policy: personaPolicy{
+				DeniedCategories: []string{"High-Interaction"},
+			},
+			wantErr: `persona 501c5e5a-cf2e-4401-844a-04d4391b1332 (Cisco ASA) is of denied category "high-interaction"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.policy.Check(persona)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
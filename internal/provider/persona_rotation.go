@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"fmt"
+	"time"
+)
+
+// personaRotation rotates personas in order, one per period since the start time.
+type personaRotation struct {
+	PersonaIDs []string
+	Start      time.Time
+	Period     time.Duration
+}
+
+// newPersonaRotation parses the start time, in RFC 3339 format, and the period, as a Go duration.
+func newPersonaRotation(personaIDs []string, start, period string) (personaRotation, error) {
+	rotation := personaRotation{PersonaIDs: personaIDs}
+
+	if len(personaIDs) == 0 {
+		return rotation, fmt.Errorf("at least one persona is required")
+	}
+
+	var err error
+
+	rotation.Start, err = time.Parse(time.RFC3339, start)
+	if err != nil {
+		return rotation, fmt.Errorf("invalid start time %q, expected RFC 3339 format", start)
+	}
+
+	rotation.Period, err = time.ParseDuration(period)
+	if err != nil || rotation.Period <= 0 {
+		return rotation, fmt.Errorf("invalid rotation period %q, expected a positive duration such as 168h", period)
+	}
+
+	return rotation, nil
+}
+
+// Slot returns the index of the persona active at the given time and the time of the next rotation.
+// The first persona is active until the end of the first period, including before the start time.
+func (r personaRotation) Slot(now time.Time) (int, time.Time) {
+	var n int64
+	if elapsed := now.Sub(r.Start); elapsed > 0 {
+		n = int64(elapsed / r.Period)
+	}
+
+	return int(n % int64(len(r.PersonaIDs))), r.Start.Add(time.Duration(n+1) * r.Period)
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonaRotation_Slot(t *testing.T) {
+	t.Parallel()
+
+	rotation, err := newPersonaRotation([]string{"a", "b", "c"}, "2024-08-26T00:00:00Z", "168h")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 8, 26, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	testCases := []struct {
+		name      string
+		now       time.Time
+		wantIndex int
+		wantNext  time.Time
+	}{
+		{
+			name:      "before start",
+			now:       start.Add(-time.Hour),
+			wantIndex: 0,
+			wantNext:  start.Add(week),
+		},
+		{
+			name:      "first period",
+			now:       start.Add(time.Hour),
+			wantIndex: 0,
+			wantNext:  start.Add(week),
+		},
+		{
+			name:      "second period",
+			now:       start.Add(week),
+			wantIndex: 1,
+			wantNext:  start.Add(2 * week),
+		},
+		{
+			name:      "wraps around",
+			now:       start.Add(4*week + time.Hour),
+			wantIndex: 1,
+			wantNext:  start.Add(5 * week),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			index, next := rotation.Slot(tc.now)
+			assert.Equal(t, tc.wantIndex, index)
+			assert.Equal(t, tc.wantNext, next)
+		})
+	}
+}
+
+func TestNewPersonaRotation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		personaIDs []string
+		start      string
+		period     string
+		wantErr    string
+	}{
+		{
+			name:       "valid",
+			personaIDs: []string{"a"},
+			start:      "2024-08-26T00:00:00Z",
+			period:     "24h",
+		},
+		{
+			name:    "no personas",
+			start:   "2024-08-26T00:00:00Z",
+			period:  "24h",
+			wantErr: "at least one persona is required",
+		},
+		{
+			name:       "invalid start",
+			personaIDs: []string{"a"},
+			start:      "2024-08-26",
+			period:     "24h",
+			wantErr:    `invalid start time "2024-08-26"`,
+		},
+		{
+			name:       "invalid period",
+			personaIDs: []string{"a"},
+			start:      "2024-08-26T00:00:00Z",
+			period:     "1w",
+			wantErr:    `invalid rotation period "1w"`,
+		},
+		{
+			name:       "zero period",
+			personaIDs: []string{"a"},
+			start:      "2024-08-26T00:00:00Z",
+			period:     "0s",
+			wantErr:    `invalid rotation period "0s"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newPersonaRotation(tc.personaIDs, tc.start, tc.period)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -4,33 +4,62 @@ import (
 	"context"
 	"fmt"
 	"net/url"
-	"os"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
 )
 
 const (
 	envVarAPIKey = "GN_API_KEY"
+
+	// Default timeouts of resources, unless set in their timeouts block.
+	defaultCreateTimeout = 20 * time.Minute
+	defaultReadTimeout   = 5 * time.Minute
+	defaultUpdateTimeout = 20 * time.Minute
+	defaultDeleteTimeout = 20 * time.Minute
 )
 
 // Ensure GreyNoiseProvider satisfies various provider interfaces.
 var _ provider.Provider = &GreyNoiseProvider{}
 var _ provider.ProviderWithFunctions = &GreyNoiseProvider{}
+var _ provider.ProviderWithEphemeralResources = &GreyNoiseProvider{}
 
 type GreyNoiseProvider struct {
 	version string
 }
 
 type GreyNoiseProviderModel struct {
-	BaseURL types.String `tfsdk:"base_url"`
-	APIKey  types.String `tfsdk:"api_key"`
+	BaseURL         types.String `tfsdk:"base_url"`
+	APIKey          types.String `tfsdk:"api_key"`
+	Profile         types.String `tfsdk:"profile"`
+	CredentialsFile types.String `tfsdk:"credentials_file"`
+	APIKeyCommand   types.List   `tfsdk:"api_key_command"`
+
+	ExpectedWorkspaceID types.String `tfsdk:"expected_workspace_id"`
+	ExpectedUserID      types.String `tfsdk:"expected_user_id"`
+
+	ReadOnly     types.Bool   `tfsdk:"read_only"`
+	DryRun       types.Bool   `tfsdk:"dry_run"`
+	AuditLogPath types.String `tfsdk:"audit_log_path"`
+
+	DefaultMetadata types.Map `tfsdk:"default_metadata"`
+
+	AllowedPersonaIDs types.List `tfsdk:"allowed_persona_ids"`
+	AllowedTiers      types.List `tfsdk:"allowed_tiers"`
+	DeniedCategories  types.List `tfsdk:"denied_categories"`
 }
 
 func (p *GreyNoiseProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -42,9 +71,81 @@ func (p *GreyNoiseProvider) Schema(_ context.Context, req provider.SchemaRequest
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"api_key": schema.StringAttribute{
-				MarkdownDescription: "GreyNoise API Key.",
-				Optional:            true,
-				Sensitive:           true,
+				MarkdownDescription: fmt.Sprintf("GreyNoise API Key. If not set, the API key is looked up in order in "+
+					"the `%s` environment variable, the credentials file and from `api_key_command`.", envVarAPIKey),
+				Optional:  true,
+				Sensitive: true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Profile of the credentials file to read the API key from. "+
+					"Can also be set via the `%s` environment variable. Defaults to `%s`.",
+					envVarProfile, defaultProfile),
+				Optional: true,
+			},
+			"credentials_file": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Path of the credentials file, with a `[profile]` section per "+
+					"profile holding its `%s`. Can also be set via the `%s` environment variable. Defaults to `%s`.",
+					credentialsAPIKey, envVarCredentialsFile, defaultCredentialsFile),
+				Optional: true,
+			},
+			"api_key_command": schema.ListAttribute{
+				ElementType: types.StringType,
+				MarkdownDescription: "Command, as a list of the program and its arguments, " +
+					"that outputs the API key, e.g. a secrets helper.",
+				Optional: true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"expected_workspace_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the workspace the API key is expected to belong to. " +
+					"If set, the provider fails to configure when the API key belongs to another workspace.",
+				Optional: true,
+			},
+			"expected_user_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the user the API key is expected to belong to. " +
+					"If set, the provider fails to configure when the API key belongs to another user.",
+				Optional: true,
+			},
+			"read_only": schema.BoolAttribute{
+				MarkdownDescription: "Refuse any change to GreyNoise resources. Changes fail with an error, " +
+					"while reads, and so plans, keep working.",
+				Optional: true,
+			},
+			"dry_run": schema.BoolAttribute{
+				MarkdownDescription: "Log the requests that would change GreyNoise resources instead of sending them. " +
+					"Changes are reported as successful.",
+				Optional: true,
+			},
+			"default_metadata": schema.MapAttribute{
+				ElementType: types.StringType,
+				MarkdownDescription: "Metadata set on every sensor updated by the provider, replacing existing " +
+					"values, e.g. `managed_by = \"terraform\"`. Values set on `greynoise_sensor_metadata` take precedence.",
+				Optional: true,
+			},
+			"allowed_persona_ids": schema.ListAttribute{
+				ElementType: types.StringType,
+				MarkdownDescription: "IDs of the personas allowed to be applied to sensors. " +
+					"If set, applying other personas fails at plan time.",
+				Optional: true,
+			},
+			"allowed_tiers": schema.ListAttribute{
+				ElementType: types.StringType,
+				MarkdownDescription: "Tiers of the personas allowed to be applied to sensors, e.g. `community`. " +
+					"If set, applying personas of other tiers fails at plan time.",
+				Optional: true,
+			},
+			"denied_categories": schema.ListAttribute{
+				ElementType: types.StringType,
+				MarkdownDescription: "Categories of the personas denied from being applied to sensors. " +
+					"Applying personas of any of those categories fails at plan time.",
+				Optional: true,
+			},
+			"audit_log_path": schema.StringAttribute{
+				MarkdownDescription: "Path of a file to append a JSON line to for each request changing GreyNoise " +
+					"resources, with the time, method, path, sensor ID, request body with secrets redacted, " +
+					"outcome (`sent`, `refused` with `read_only` or `dry_run`), status and duration.",
+				Optional: true,
 			},
 			"base_url": schema.StringAttribute{
 				MarkdownDescription: "GreyNoise API Base URL.",
@@ -62,24 +163,16 @@ func (p *GreyNoiseProvider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 
-	// Check environment variable
-	var apiKey string
-	if config.APIKey.IsNull() {
-		apiKey = os.Getenv(envVarAPIKey)
-	} else {
-		apiKey = config.APIKey.ValueString()
-	}
-
-	if apiKey == "" {
-		resp.Diagnostics.AddError(
-			"No API key set",
-			fmt.Sprintf("API key must be provided in configuration or set via environment variable: %s",
-				envVarAPIKey),
-		)
-
+	apiKey, apiKeySource, diags := resolveAPIKey(ctx, config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	tflog.Info(ctx, "Using GreyNoise API key", map[string]interface{}{
+		"source": apiKeySource,
+	})
+
 	// Validate parameters and create client
 	var options []client.Option
 
@@ -97,6 +190,15 @@ func (p *GreyNoiseProvider) Configure(ctx context.Context, req provider.Configur
 		options = append(options, client.WithBaseURL(baseURL))
 	}
 
+	options = append(options,
+		client.WithReadOnly(config.ReadOnly.ValueBool()),
+		client.WithDryRun(config.DryRun.ValueBool()),
+	)
+
+	if !config.AuditLogPath.IsNull() {
+		options = append(options, client.WithAuditLogPath(config.AuditLogPath.ValueString()))
+	}
+
 	c, err := client.New(apiKey, options...)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -107,33 +209,106 @@ func (p *GreyNoiseProvider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 
+	// Guard against an API key pointing the configuration at another account
+	for _, expected := range []struct {
+		attribute string
+		name      string
+		value     types.String
+		actual    uuid.UUID
+	}{
+		{attribute: "expected_workspace_id", name: "workspace", value: config.ExpectedWorkspaceID, actual: c.WorkspaceID()},
+		{attribute: "expected_user_id", name: "user", value: config.ExpectedUserID, actual: c.UserID()},
+	} {
+		if expected.value.IsNull() {
+			continue
+		}
+
+		expectedID, err := uuid.Parse(expected.value.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root(expected.attribute),
+				fmt.Sprintf("Invalid expected %s ID", expected.name),
+				fmt.Sprintf("Error attempting to parse %s: %s", expected.attribute, err.Error()),
+			)
+
+			continue
+		}
+
+		if expectedID != expected.actual {
+			resp.Diagnostics.AddAttributeError(path.Root(expected.attribute),
+				fmt.Sprintf("Unexpected GreyNoise %s", expected.name),
+				fmt.Sprintf("API key belongs to %s %s, expected: %s", expected.name, expected.actual, expectedID),
+			)
+		}
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	data := &Data{
 		APIKey: apiKey,
 		Client: c,
 	}
 
+	if !config.DefaultMetadata.IsNull() {
+		resp.Diagnostics.Append(config.DefaultMetadata.ElementsAs(ctx, &data.DefaultMetadata, false)...)
+	}
+
+	for _, policy := range []struct {
+		value  types.List
+		target *[]string
+	}{
+		{value: config.AllowedPersonaIDs, target: &data.PersonaPolicy.AllowedPersonaIDs},
+		{value: config.AllowedTiers, target: &data.PersonaPolicy.AllowedTiers},
+		{value: config.DeniedCategories, target: &data.PersonaPolicy.DeniedCategories},
+	} {
+		if !policy.value.IsNull() {
+			resp.Diagnostics.Append(policy.value.ElementsAs(ctx, policy.target, false)...)
+		}
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	resp.DataSourceData = data
 	resp.ResourceData = data
+	resp.EphemeralResourceData = data
 }
 
 func (p *GreyNoiseProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewSensorBootstrapResource,
+		NewSensorFleetPersonaResource,
 		NewSensorMetadataResource,
 		NewSensorPersonaResource,
+		NewSensorPersonaRotationResource,
+		NewSensorStateResource,
+	}
+}
+
+func (p *GreyNoiseProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewSensorBootstrapCredentialsEphemeralResource,
 	}
 }
 
 func (p *GreyNoiseProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewAccountDataSource,
+		NewPersonaIngressRulesDataSource,
 		NewPersonasDataSource,
 		NewSensorDataSource,
 	}
 }
 
 func (p *GreyNoiseProvider) Functions(_ context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewBootstrapCommandFunction,
+		NewIngressRulesFunction,
+		NewParseIPsFunction,
+		NewSSHPortFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -6,10 +6,12 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"testing"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/stretchr/testify/require"
 
 	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
 )
@@ -74,7 +76,11 @@ func (m *mockAPIServer) RegisterMatch(method string, path string, match func(*ur
 }
 
 func (m *mockAPIServer) Server() *httptest.Server {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return httptest.NewServer(m.Handler())
+}
+
+func (m *mockAPIServer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimSpace(r.URL.Path)
 
 		if r.Header.Get(client.HeaderKey) != m.APIKey {
@@ -101,10 +107,18 @@ func (m *mockAPIServer) Server() *httptest.Server {
 					continue
 				}
 
-				w.Header().Set("Content-Type", "application/json")
+				// Raw bodies (e.g. scripts) are written as is, everything else is JSON encoded.
+				if raw, ok := endpoint.body().([]byte); ok {
+					w.Header().Set("Content-Type", "text/plain")
 
-				w.WriteHeader(endpoint.status)
-				_ = json.NewEncoder(w).Encode(endpoint.body())
+					w.WriteHeader(endpoint.status)
+					_, _ = w.Write(raw)
+				} else {
+					w.Header().Set("Content-Type", "application/json")
+
+					w.WriteHeader(endpoint.status)
+					_ = json.NewEncoder(w).Encode(endpoint.body())
+				}
 
 				if endpoint.callback != nil {
 					endpoint.callback(r)
@@ -115,9 +129,7 @@ func (m *mockAPIServer) Server() *httptest.Server {
 		}
 
 		http.NotFoundHandler().ServeHTTP(w, r)
-	}))
-
-	return server
+	})
 }
 
 func body(b interface{}) func() interface{} {
@@ -129,3 +141,14 @@ func body(b interface{}) func() interface{} {
 func emptyBody() interface{} {
 	return nil
 }
+
+// testClient returns a client of the API at baseURL, for tests calling resources directly.
+func testClient(t *testing.T, baseURL string) *client.GreyNoiseClient {
+	u, err := url.Parse(baseURL)
+	require.NoError(t, err)
+
+	c, err := client.New(testAPIKey, client.WithBaseURL(u))
+	require.NoError(t, err)
+
+	return c
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ ephemeral.EphemeralResource = &SensorBootstrapCredentialsEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &SensorBootstrapCredentialsEphemeralResource{}
+
+func NewSensorBootstrapCredentialsEphemeralResource() ephemeral.EphemeralResource {
+	return &SensorBootstrapCredentialsEphemeralResource{}
+}
+
+type SensorBootstrapCredentialsEphemeralResource struct {
+	data *Data
+}
+
+type SensorBootstrapCredentialsEphemeralResourceModel struct {
+	HTTPProxy   types.String `tfsdk:"http_proxy"`
+	HTTPSProxy  types.String `tfsdk:"https_proxy"`
+	NoProxy     types.String `tfsdk:"no_proxy"`
+	APIKey      types.String `tfsdk:"api_key"`
+	SetupScript types.String `tfsdk:"setup_script"`
+
+	EnvironmentFile types.String `tfsdk:"environment_file"`
+}
+
+func (r *SensorBootstrapCredentialsEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_sensor_bootstrap_credentials"
+}
+
+func (r *SensorBootstrapCredentialsEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Sensor bootstrap credentials ephemeral resource provides the API key and the script that sets up the server environment, only for the duration of a Terraform run.
+
+It can be used with provisioners in place of ` + "`setup_script`" + ` of ` + "`greynoise_sensor_bootstrap`" + `, so the API key is never persisted in the plan or state. Requires Terraform 1.10 or later.`,
+		Attributes: map[string]schema.Attribute{
+			"http_proxy": schema.StringAttribute{
+				MarkdownDescription: "Proxy used by the server for HTTP egress, same as in `greynoise_sensor_bootstrap`.",
+				Optional:            true,
+			},
+			"https_proxy": schema.StringAttribute{
+				MarkdownDescription: "Proxy used by the server for HTTPS egress, same as in `greynoise_sensor_bootstrap`.",
+				Optional:            true,
+			},
+			"no_proxy": schema.StringAttribute{
+				MarkdownDescription: "Comma-separated list of hosts the server reaches without a proxy, " +
+					"same as in `greynoise_sensor_bootstrap`.",
+				Optional: true,
+			},
+			"api_key": schema.StringAttribute{
+				MarkdownDescription: "GreyNoise API key.",
+				Sensitive:           true,
+				Computed:            true,
+			},
+			"setup_script": schema.StringAttribute{
+				MarkdownDescription: "Script that sets up the server environment, " +
+					"to run before `bootstrap_script` of `greynoise_sensor_bootstrap`.",
+				Sensitive: true,
+				Computed:  true,
+			},
+			"environment_file": schema.StringAttribute{
+				MarkdownDescription: "Environment file, with the API key and proxies, of the sensor container run " +
+					"by the `systemd` and `container` deployment modes of `greynoise_sensor_bootstrap`. To write to `" +
+					sensorEnvironmentFilePath + "` with mode `0600`, or to create the `" + sensorContainerName +
+					"` Kubernetes Secret from.",
+				Sensitive: true,
+				Computed:  true,
+			},
+		},
+	}
+}
+
+func (r *SensorBootstrapCredentialsEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*Data)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("expected *Data, got: %T. Please report this issue to the provider developers.",
+				req.ProviderData),
+		)
+
+		return
+	}
+
+	r.data = data
+}
+
+func (r *SensorBootstrapCredentialsEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data SensorBootstrapCredentialsEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.APIKey = types.StringValue(r.data.APIKey)
+	data.SetupScript = types.StringValue(
+		setupScript(r.data.APIKey, proxyExports(data.HTTPProxy, data.HTTPSProxy, data.NoProxy)),
+	)
+	data.EnvironmentFile = types.StringValue(
+		sensorEnvironmentFile(r.data.APIKey, data.HTTPProxy, data.HTTPSProxy, data.NoProxy),
+	)
+
+	tflog.Trace(ctx, "Opened sensor bootstrap credentials ephemeral resource")
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccSensorBootstrapCredentialsEphemeralResource(t *testing.T) {
+	t.Parallel()
+
+	mockServer := defaultMockAPIServer()
+	mockAPIKey := mockServer.APIKey
+
+	server := mockServer.Server()
+
+	testCases := []struct {
+		name            string
+		config          string
+		setupScript     string
+		environmentFile string
+	}{
+		{
+			name:            "min parameters",
+			config:          `ephemeral "greynoise_sensor_bootstrap_credentials" "this" {}`,
+			setupScript:     fmt.Sprintf("echo %s > ~/.greynoise.key", mockAPIKey),
+			environmentFile: fmt.Sprintf("GREYNOISE_API_KEY=%s\n", mockAPIKey),
+		},
+		{
+			name: "proxy",
+			config: `
+			ephemeral "greynoise_sensor_bootstrap_credentials" "this" {
+			  http_proxy = "http://proxy.internal:3128"
+			}`,
+			setupScript: fmt.Sprintf(`install -m 600 /dev/null ~/.greynoise.proxy && echo 'export http_proxy=http://proxy.internal:3128 HTTP_PROXY=http://proxy.internal:3128' > ~/.greynoise.proxy && \
+echo %s > ~/.greynoise.key`, mockAPIKey),
+			environmentFile: fmt.Sprintf("GREYNOISE_API_KEY=%s\n"+
+				"http_proxy=http://proxy.internal:3128\nHTTP_PROXY=http://proxy.internal:3128\n", mockAPIKey),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resource.Test(t, resource.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_10_0),
+				},
+				ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+					"greynoise": providerserver.NewProtocol6WithError(New("test")()),
+					"echo":      echoprovider.NewProviderServer(),
+				},
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(`
+						provider "greynoise" {
+						  base_url = "%s"
+						  api_key  = "%s"
+						}
+						`, server.URL, mockAPIKey) + tc.config + `
+
+						provider "echo" {
+						  data = ephemeral.greynoise_sensor_bootstrap_credentials.this
+						}
+
+						resource "echo" "this" {}`,
+						ConfigStateChecks: []statecheck.StateCheck{
+							statecheck.ExpectKnownValue("echo.this", tfjsonpath.New("data").AtMapKey("api_key"),
+								knownvalue.StringExact(mockAPIKey)),
+							statecheck.ExpectKnownValue("echo.this", tfjsonpath.New("data").AtMapKey("setup_script"),
+								knownvalue.StringExact(tc.setupScript)),
+							statecheck.ExpectKnownValue("echo.this",
+								tfjsonpath.New("data").AtMapKey("environment_file"),
+								knownvalue.StringExact(tc.environmentFile)),
+						},
+					},
+				},
+			})
+		})
+	}
+}
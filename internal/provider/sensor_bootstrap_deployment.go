@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DeploymentModeHost      = "host"
+	DeploymentModeSystemd   = "systemd"
+	DeploymentModeContainer = "container"
+
+	sensorContainerName = "greynoise-sensor"
+
+	// sensorEnvironmentFilePath is the environment file the sensor container reads the API key and the proxy
+	// environment from, so that the rendered deployments hold no secret. It is expected to be written with
+	// mode 0600.
+	sensorEnvironmentFilePath = "/etc/greynoise/sensor.env"
+
+	apiKeyEnv = "GREYNOISE_API_KEY"
+)
+
+var deploymentModes = []string{DeploymentModeHost, DeploymentModeSystemd, DeploymentModeContainer}
+
+// sensorDeployment renders the deployment of the sensor image on a server, for deployment modes other than host.
+// The image is run with the bootstrap script arguments, and reads the API key and the proxies from
+// its environment, see sensorEnvironmentFile.
+type sensorDeployment struct {
+	manifest bootstrapManifest
+	image    string
+}
+
+// SystemdUnit renders a unit running the sensor container with Docker, on the host network.
+func (d sensorDeployment) SystemdUnit() string {
+	return fmt.Sprintf(`[Unit]
+Description=GreyNoise sensor
+Wants=network-online.target
+After=network-online.target docker.service
+Requires=docker.service
+
+[Service]
+Restart=always
+ExecStartPre=-/usr/bin/docker rm -f %[1]s
+ExecStart=/usr/bin/docker run --rm --name %[1]s --network host --cap-add NET_ADMIN --env-file %[2]s %[3]s %[4]s
+ExecStop=/usr/bin/docker stop %[1]s
+
+[Install]
+WantedBy=multi-user.target
+`,
+		sensorContainerName, sensorEnvironmentFilePath, d.image, strings.Join(d.manifest.Arguments, " "),
+	)
+}
+
+// DockerCompose renders a compose file running the sensor container on the host network.
+func (d sensorDeployment) DockerCompose() (string, error) {
+	return marshalYAMLDocuments(map[string]interface{}{
+		"services": map[string]interface{}{
+			sensorContainerName: map[string]interface{}{
+				"image":        d.image,
+				"restart":      "unless-stopped",
+				"network_mode": "host",
+				"cap_add":      []string{"NET_ADMIN"},
+				"env_file":     []string{sensorEnvironmentFilePath},
+				"command":      d.manifest.Arguments,
+			},
+		},
+	})
+}
+
+// KubernetesManifest renders a single replica Deployment running the sensor container on the host network,
+// with its environment from a Secret of the same name, to create from the environment file.
+func (d sensorDeployment) KubernetesManifest() (string, error) {
+	labels := map[string]string{"app.kubernetes.io/name": sensorContainerName}
+
+	return marshalYAMLDocuments(map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": sensorContainerName, "labels": labels},
+		"spec": map[string]interface{}{
+			"replicas": 1,
+			"strategy": map[string]string{"type": "Recreate"},
+			"selector": map[string]interface{}{"matchLabels": labels},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": labels},
+				"spec": map[string]interface{}{
+					"hostNetwork": true,
+					"containers": []map[string]interface{}{
+						{
+							"name":  sensorContainerName,
+							"image": d.image,
+							"args":  d.manifest.Arguments,
+							"envFrom": []map[string]interface{}{
+								{"secretRef": map[string]string{"name": sensorContainerName}},
+							},
+							"securityContext": map[string]interface{}{
+								"capabilities": map[string][]string{"add": {"NET_ADMIN"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// sensorEnvironmentFile renders the environment file of the sensor container, holding the API key and
+// the proxy environment, both lower and upper case.
+func sensorEnvironmentFile(apiKey string, httpProxy, httpsProxy, noProxy types.String) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s=%s\n", apiKeyEnv, apiKey)
+
+	for _, env := range []struct {
+		name  string
+		value types.String
+	}{
+		{name: "http_proxy", value: httpProxy},
+		{name: "https_proxy", value: httpsProxy},
+		{name: "no_proxy", value: noProxy},
+	} {
+		if env.value.IsNull() {
+			continue
+		}
+
+		fmt.Fprintf(&b, "%s=%s\n%s=%s\n", env.name, env.value.ValueString(),
+			strings.ToUpper(env.name), env.value.ValueString())
+	}
+
+	return b.String()
+}
+
+// marshalYAMLDocuments renders documents as a YAML stream, indented as usual for manifests.
+func marshalYAMLDocuments(docs ...interface{}) (string, error) {
+	var b strings.Builder
+
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return "", err
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// bootstrapManifest describes the bootstrap of a server for tooling that does not run the shell scripts.
+// Proxies are without their credentials, as the manifest is not sensitive.
+type bootstrapManifest struct {
+	BootstrapScriptURL      string   `json:"bootstrap_script_url" yaml:"bootstrap_script_url"`
+	BootstrapScriptSHA256   string   `json:"bootstrap_script_sha256" yaml:"bootstrap_script_sha256"`
+	UnBootstrapScriptURL    string   `json:"unbootstrap_script_url" yaml:"unbootstrap_script_url"`
+	UnBootstrapScriptSHA256 string   `json:"unbootstrap_script_sha256" yaml:"unbootstrap_script_sha256"`
+	SensorsURL              string   `json:"sensors_url" yaml:"sensors_url"`
+	PublicIP                string   `json:"public_ip" yaml:"public_ip"`
+	PublicIPs               []string `json:"public_ips" yaml:"public_ips"`
+	InternalIP              string   `json:"internal_ip,omitempty" yaml:"internal_ip,omitempty"`
+	SSHPort                 int32    `json:"ssh_port" yaml:"ssh_port"`
+	NAT                     bool     `json:"nat" yaml:"nat"`
+	Arguments               []string `json:"arguments" yaml:"arguments"`
+	HTTPProxy               string   `json:"http_proxy,omitempty" yaml:"http_proxy,omitempty"`
+	HTTPSProxy              string   `json:"https_proxy,omitempty" yaml:"https_proxy,omitempty"`
+	NoProxy                 string   `json:"no_proxy,omitempty" yaml:"no_proxy,omitempty"`
+}
+
+type ansibleInventory struct {
+	All ansibleGroup `yaml:"all"`
+}
+
+type ansibleGroup struct {
+	Hosts map[string]ansibleHost `yaml:"hosts"`
+}
+
+type ansibleHost struct {
+	AnsibleHost        string            `yaml:"ansible_host"`
+	GreyNoiseBootstrap bootstrapManifest `yaml:"greynoise_bootstrap"`
+}
+
+// JSON renders the manifest as a JSON document.
+func (m bootstrapManifest) JSON() (string, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// AnsibleInventory renders a YAML inventory with the server as its only host.
+func (m bootstrapManifest) AnsibleInventory() (string, error) {
+	host := m.PublicIPs[0]
+
+	b, err := yaml.Marshal(ansibleInventory{
+		All: ansibleGroup{
+			Hosts: map[string]ansibleHost{
+				host: {
+					AnsibleHost:        host,
+					GreyNoiseBootstrap: m,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
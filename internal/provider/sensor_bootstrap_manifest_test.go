@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootstrapManifest_AnsibleInventory(t *testing.T) {
+	manifest := bootstrapManifest{
+		BootstrapScriptURL:      "https://api.greynoise.io/v1/workspaces/ws/sensors/bootstrap/script",
+		BootstrapScriptSHA256:   "b1",
+		UnBootstrapScriptURL:    "https://api.greynoise.io/v1/workspaces/ws/sensors/unbootstrap/script",
+		UnBootstrapScriptSHA256: "u1",
+		SensorsURL:              "https://api.greynoise.io/v1/workspaces/ws/sensors",
+		PublicIP:                "185.108.182.240",
+		PublicIPs:               []string{"185.108.182.240"},
+		SSHPort:                 62914,
+		Arguments:               []string{"-p", "185.108.182.240", "-s", "62914"},
+	}
+
+	inventory, err := manifest.AnsibleInventory()
+	assert.NoError(t, err)
+	assert.Equal(t, `all:
+    hosts:
+        185.108.182.240:
+            ansible_host: 185.108.182.240
+            greynoise_bootstrap:
+                bootstrap_script_url: https://api.greynoise.io/v1/workspaces/ws/sensors/bootstrap/script
+                bootstrap_script_sha256: b1
+                unbootstrap_script_url: https://api.greynoise.io/v1/workspaces/ws/sensors/unbootstrap/script
+                unbootstrap_script_sha256: u1
+                sensors_url: https://api.greynoise.io/v1/workspaces/ws/sensors
+                public_ip: 185.108.182.240
+                public_ips:
+                    - 185.108.182.240
+                ssh_port: 62914
+                nat: false
+                arguments:
+                    - -p
+                    - 185.108.182.240
+                    - -s
+                    - "62914"
+`, inventory)
+}
@@ -0,0 +1,285 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultSSHPort = 22
+
+	sshDialTimeout   = 10 * time.Second
+	sshRetryInterval = 5 * time.Second
+
+	sshWaitForCloudInitCommand = "if command -v cloud-init > /dev/null; then cloud-init status --wait > /dev/null; fi"
+	sshConfirmBootstrapCommand = "test -s /opt/greynoise/sensor.id"
+)
+
+type SensorBootstrapSSHModel struct {
+	Host                  types.String `tfsdk:"host"`
+	User                  types.String `tfsdk:"user"`
+	PrivateKeyFile        types.String `tfsdk:"private_key_file"`
+	Port                  types.Int32  `tfsdk:"port"`
+	HostKey               types.String `tfsdk:"host_key"`
+	InsecureIgnoreHostKey types.Bool   `tfsdk:"insecure_ignore_host_key"`
+}
+
+// sshTarget is a server that is bootstrapped over SSH.
+type sshTarget struct {
+	host          string
+	clientConfig  *ssh.ClientConfig
+	initialPort   int32
+	bootstrapPort int32
+	retryInterval time.Duration
+}
+
+// sshCommandError is returned when a remote command exits with a non-zero status.
+type sshCommandError struct {
+	exitStatus int
+	stderr     string
+}
+
+func (e *sshCommandError) Error() string {
+	return fmt.Sprintf("exit status %d", e.exitStatus)
+}
+
+// sshHandshakeError is returned when the server is reachable but the SSH handshake fails,
+// for example because the host key does not match or the private key is rejected.
+type sshHandshakeError struct {
+	err error
+}
+
+func (e *sshHandshakeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sshHandshakeError) Unwrap() error {
+	return e.err
+}
+
+func newSSHTarget(model *SensorBootstrapSSHModel, bootstrapPort int32) (*sshTarget, diag.Diagnostics) {
+	// The private key is read when connecting, rather than configured, so that it is not stored in the state.
+	privateKey, err := os.ReadFile(model.PrivateKeyFile.ValueString())
+	if err != nil {
+		return nil, diag.Diagnostics{
+			diag.NewErrorDiagnostic("SSH configuration error",
+				fmt.Sprintf("Error occurred while reading private key: %s", err.Error())),
+		}
+	}
+
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, diag.Diagnostics{
+			diag.NewErrorDiagnostic("SSH configuration error",
+				fmt.Sprintf("Error occurred while parsing private key: %s", err.Error())),
+		}
+	}
+
+	// The configuration is validated to either have a host key or explicitly ignore it.
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if !model.InsecureIgnoreHostKey.ValueBool() {
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(model.HostKey.ValueString()))
+		if err != nil {
+			return nil, diag.Diagnostics{
+				diag.NewErrorDiagnostic("SSH configuration error",
+					fmt.Sprintf("Error occurred while parsing host key: %s", err.Error())),
+			}
+		}
+
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	}
+
+	initialPort := int32(defaultSSHPort)
+	if !model.Port.IsNull() {
+		initialPort = model.Port.ValueInt32()
+	}
+
+	return &sshTarget{
+		host: model.Host.ValueString(),
+		clientConfig: &ssh.ClientConfig{
+			User:            model.User.ValueString(),
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         sshDialTimeout,
+		},
+		initialPort:   initialPort,
+		bootstrapPort: bootstrapPort,
+		retryInterval: sshRetryInterval,
+	}, nil
+}
+
+// Bootstrap runs the setup and bootstrap scripts on the initial SSH port and then confirms,
+// on the SSH port selected for the sensor, that bootstrapping succeeded.
+func (t *sshTarget) Bootstrap(ctx context.Context, setupScript, bootstrapScript string) diag.Diagnostics {
+	conn, err := t.dial(ctx, t.initialPort)
+	if err != nil {
+		return t.errorDiagnostics("connecting", t.initialPort, err)
+	}
+	defer conn.Close()
+
+	for _, step := range []struct {
+		name    string
+		command string
+	}{
+		{name: "waiting for cloud-init", command: sshWaitForCloudInitCommand},
+		{name: "running setup script", command: setupScript},
+	} {
+		if err := t.run(ctx, conn, step.command); err != nil {
+			return t.errorDiagnostics(step.name, t.initialPort, err)
+		}
+	}
+
+	// The connection is expected to drop once bootstrap moves SSH to the selected port.
+	if err := t.run(ctx, conn, bootstrapScript); err != nil {
+		var cmdErr *sshCommandError
+		if errors.As(err, &cmdErr) {
+			return t.errorDiagnostics("running bootstrap script", t.initialPort, err)
+		}
+
+		tflog.Debug(ctx, "SSH connection lost during bootstrap", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	confirmConn, err := t.dial(ctx, t.bootstrapPort)
+	if err != nil {
+		return t.errorDiagnostics("reconnecting after bootstrap", t.bootstrapPort, err)
+	}
+	defer confirmConn.Close()
+
+	if err := t.run(ctx, confirmConn, sshConfirmBootstrapCommand); err != nil {
+		return t.errorDiagnostics("confirming bootstrap", t.bootstrapPort, err)
+	}
+
+	return nil
+}
+
+// UnBootstrap runs the unbootstrap script on the SSH port selected for the sensor.
+func (t *sshTarget) UnBootstrap(ctx context.Context, unBootstrapScript string) diag.Diagnostics {
+	conn, err := t.dial(ctx, t.bootstrapPort)
+	if err != nil {
+		return t.errorDiagnostics("connecting", t.bootstrapPort, err)
+	}
+	defer conn.Close()
+
+	// The connection is expected to drop once unbootstrap moves SSH back to its original port.
+	if err := t.run(ctx, conn, unBootstrapScript); err != nil {
+		var cmdErr *sshCommandError
+		if errors.As(err, &cmdErr) {
+			return t.errorDiagnostics("running unbootstrap script", t.bootstrapPort, err)
+		}
+
+		tflog.Debug(ctx, "SSH connection lost during unbootstrap", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return nil
+}
+
+// dial connects to the server, retrying network errors until the context is done as the server might still
+// be booting. Handshake errors, such as a host key mismatch or a rejected private key, are returned immediately.
+func (t *sshTarget) dial(ctx context.Context, port int32) (*ssh.Client, error) {
+	addr := net.JoinHostPort(t.host, strconv.Itoa(int(port)))
+
+	for {
+		conn, err := t.dialOnce(ctx, addr)
+		if err == nil {
+			return conn, nil
+		}
+
+		var handshakeErr *sshHandshakeError
+		if errors.As(err, &handshakeErr) {
+			return nil, err
+		}
+
+		tflog.Debug(ctx, "SSH connection failed, retrying", map[string]interface{}{
+			"address": addr,
+			"error":   err.Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-time.After(t.retryInterval):
+		}
+	}
+}
+
+func (t *sshTarget) dialOnce(ctx context.Context, addr string) (*ssh.Client, error) {
+	dialer := net.Dialer{Timeout: t.clientConfig.Timeout}
+
+	netConn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, addr, t.clientConfig)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, &sshHandshakeError{err: err}
+	}
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+func (t *sshTarget) run(ctx context.Context, conn *ssh.Client, command string) error {
+	session, err := conn.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(command)
+	}()
+
+	select {
+	case <-ctx.Done():
+		_ = conn.Close()
+		return ctx.Err()
+	case err = <-done:
+	}
+
+	tflog.Debug(ctx, "SSH command completed", map[string]interface{}{
+		"stdout": stdout.String(),
+		"stderr": stderr.String(),
+	})
+
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return &sshCommandError{exitStatus: exitErr.ExitStatus(), stderr: stderr.String()}
+	}
+
+	return err
+}
+
+func (t *sshTarget) errorDiagnostics(step string, port int32, err error) diag.Diagnostics {
+	detail := fmt.Sprintf("Error occurred while %s over SSH (%s): %s", step,
+		net.JoinHostPort(t.host, strconv.Itoa(int(port))), err.Error())
+
+	var cmdErr *sshCommandError
+	if errors.As(err, &cmdErr) && cmdErr.stderr != "" {
+		detail += fmt.Sprintf("\n\nRemote stderr:\n%s", cmdErr.stderr)
+	}
+
+	return diag.Diagnostics{
+		diag.NewErrorDiagnostic("SSH bootstrap error", detail),
+	}
+}
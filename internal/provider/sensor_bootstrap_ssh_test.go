@@ -0,0 +1,352 @@
+package provider
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testSSHServer is an in-process SSH server that records executed commands.
+type testSSHServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	hostKey  ssh.PublicKey
+	handler  func(command string) (stderr string, exitStatus uint32)
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func newTestSSHServer(t *testing.T, hostSigner ssh.Signer, authorizedKey ssh.PublicKey,
+	handler func(command string) (string, uint32)) *testSSHServer {
+	t.Helper()
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(authorizedKey.Marshal()) {
+				return nil, errors.New("unauthorized")
+			}
+
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	server := &testSSHServer{
+		listener: listener,
+		config:   config,
+		hostKey:  hostSigner.PublicKey(),
+		handler:  handler,
+	}
+
+	go server.serve()
+
+	return server
+}
+
+func (s *testSSHServer) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *testSSHServer) HostKey() string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(s.hostKey)))
+}
+
+func (s *testSSHServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.commands...)
+}
+
+func (s *testSSHServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handleConn(conn)
+	}
+}
+
+func (s *testSSHServer) handleConn(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		go s.handleSession(channel, requests)
+	}
+}
+
+func (s *testSSHServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for req := range requests {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+
+		var payload struct {
+			Command string
+		}
+		if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+			_ = req.Reply(false, nil)
+			continue
+		}
+
+		_ = req.Reply(true, nil)
+
+		s.mu.Lock()
+		s.commands = append(s.commands, payload.Command)
+		s.mu.Unlock()
+
+		stderr, exitStatus := s.handler(payload.Command)
+		_, _ = channel.Stderr().Write([]byte(stderr))
+		_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct {
+			Status uint32
+		}{exitStatus}))
+
+		return
+	}
+}
+
+func TestAccSensorBootstrapResource_SSH(t *testing.T) {
+	t.Parallel()
+
+	testBootstrapScript := []byte("#!/usr/bin/env bash\necho bootstrap\n")
+	testUnBootstrapScript := []byte("#!/usr/bin/env bash\necho unbootstrap\n")
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/bootstrap/script", mockWorkspaceID),
+		http.StatusOK,
+		body(testBootstrapScript),
+		nil,
+	)
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/unbootstrap/script", mockWorkspaceID),
+		http.StatusOK,
+		body(testUnBootstrapScript),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	_, clientPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	clientSigner, err := ssh.NewSignerFromKey(clientPrivateKey)
+	require.NoError(t, err)
+
+	clientPEM, err := ssh.MarshalPrivateKey(clientPrivateKey, "")
+	require.NoError(t, err)
+
+	privateKeyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(privateKeyFile, pem.EncodeToMemory(clientPEM), 0o600))
+
+	_, hostPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	hostSigner, err := ssh.NewSignerFromKey(hostPrivateKey)
+	require.NoError(t, err)
+
+	_, otherPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherSigner, err := ssh.NewSignerFromKey(otherPrivateKey)
+	require.NoError(t, err)
+
+	succeed := func(string) (string, uint32) {
+		return "", 0
+	}
+	failBootstrap := func(command string) (string, uint32) {
+		if strings.Contains(command, "/sensors/bootstrap/script") {
+			return "curl: (22) The requested URL returned error: 403", 22
+		}
+
+		return "", 0
+	}
+
+	testCases := []struct {
+		name             string
+		initialHandler   func(string) (string, uint32)
+		bootstrapHandler func(string) (string, uint32)
+		check            func(initial, bootstrapped *testSSHServer) resource.TestCheckFunc
+		checkDestroy     func(initial, bootstrapped *testSSHServer) resource.TestCheckFunc
+		ignoreHostKey    bool
+		wrongHostKey     bool
+		rejectClientKey  bool
+		expectError      *regexp.Regexp
+	}{
+		{
+			name:             "success",
+			initialHandler:   succeed,
+			bootstrapHandler: succeed,
+			check: func(initial, bootstrapped *testSSHServer) resource.TestCheckFunc {
+				return func(_ *terraform.State) error {
+					commands := initial.Commands()
+					if len(commands) != 3 {
+						return fmt.Errorf("expected 3 commands on initial port, got: %v", commands)
+					}
+
+					if commands[1] != fmt.Sprintf("echo %s > ~/.greynoise.key", mockAPIKey) {
+						return fmt.Errorf("unexpected setup command: %s", commands[1])
+					}
+
+					if !strings.Contains(commands[2], "/sensors/bootstrap/script") {
+						return fmt.Errorf("unexpected bootstrap command: %s", commands[2])
+					}
+
+					if commands := bootstrapped.Commands(); len(commands) != 1 ||
+						commands[0] != sshConfirmBootstrapCommand {
+						return fmt.Errorf("expected bootstrap confirmation on selected port, got: %v", commands)
+					}
+
+					return nil
+				}
+			},
+			checkDestroy: func(_, bootstrapped *testSSHServer) resource.TestCheckFunc {
+				return func(_ *terraform.State) error {
+					commands := bootstrapped.Commands()
+					if len(commands) != 2 || !strings.Contains(commands[1], "/sensors/unbootstrap/script") {
+						return fmt.Errorf("expected unbootstrap on selected port, got: %v", commands)
+					}
+
+					return nil
+				}
+			},
+		},
+		{
+			name:             "success - host key ignored",
+			initialHandler:   succeed,
+			bootstrapHandler: succeed,
+			ignoreHostKey:    true,
+		},
+		{
+			name:             "bootstrap failure",
+			initialHandler:   failBootstrap,
+			bootstrapHandler: succeed,
+			expectError: regexp.MustCompile(`(?s)Error occurred while running bootstrap script over SSH.*` +
+				`exit status 22.*Remote stderr:.*returned error: 403`),
+		},
+		{
+			name:             "host key mismatch",
+			initialHandler:   succeed,
+			bootstrapHandler: succeed,
+			wrongHostKey:     true,
+			expectError:      regexp.MustCompile(`(?s)Error occurred while connecting over SSH.*host key mismatch`),
+		},
+		{
+			name:             "private key rejected",
+			initialHandler:   succeed,
+			bootstrapHandler: succeed,
+			rejectClientKey:  true,
+			expectError:      regexp.MustCompile(`(?s)Error occurred while connecting over SSH.*unable to authenticate`),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Both servers stand for the same host, before and after SSH is moved to the selected port, except
+			// where the initial handshake is expected to fail, so that destroy still reaches the selected port.
+			initialHostSigner, initialAuthorizedKey := hostSigner, clientSigner.PublicKey()
+			if tc.wrongHostKey {
+				initialHostSigner = otherSigner
+			}
+			if tc.rejectClientKey {
+				initialAuthorizedKey = otherSigner.PublicKey()
+			}
+
+			initial := newTestSSHServer(t, initialHostSigner, initialAuthorizedKey, tc.initialHandler)
+			bootstrapped := newTestSSHServer(t, hostSigner, clientSigner.PublicKey(), tc.bootstrapHandler)
+
+			hostKey := fmt.Sprintf("host_key = %q", bootstrapped.HostKey())
+			if tc.ignoreHostKey {
+				hostKey = "insecure_ignore_host_key = true"
+			}
+
+			step := resource.TestStep{
+				Config: fmt.Sprintf(`
+					provider "greynoise" {
+					  base_url = "%s"
+					  api_key  = "%s"
+					}
+
+					resource "greynoise_sensor_bootstrap" "this" {
+					  public_ip = "185.108.182.240"
+					  ssh_port  = %d
+
+					  ssh = {
+					    host             = "127.0.0.1"
+					    user             = "ubuntu"
+					    port             = %d
+					    private_key_file = %q
+					    %s
+					  }
+					}`, server.URL, mockAPIKey, bootstrapped.Port(), initial.Port(), privateKeyFile, hostKey),
+				ExpectError: tc.expectError,
+			}
+
+			if tc.check != nil {
+				step.Check = tc.check(initial, bootstrapped)
+			}
+
+			testCase := resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps:                    []resource.TestStep{step},
+			}
+
+			if tc.checkDestroy != nil {
+				testCase.CheckDestroy = tc.checkDestroy(initial, bootstrapped)
+			}
+
+			resource.Test(t, testCase)
+		})
+	}
+}
@@ -0,0 +1,614 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int32validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+var _ resource.Resource = &SensorFleetPersonaResource{}
+var _ resource.ResourceWithConfigValidators = &SensorFleetPersonaResource{}
+var _ resource.ResourceWithModifyPlan = &SensorFleetPersonaResource{}
+
+func NewSensorFleetPersonaResource() resource.Resource {
+	return &SensorFleetPersonaResource{}
+}
+
+type SensorFleetPersonaResource struct {
+	data *Data
+}
+
+const (
+	PersonaStatusFailed = "failed"
+
+	defaultFleetConcurrency = 10
+	sensorSearchPageSize    = 100
+)
+
+type SensorFleetPersonaResourceModel struct {
+	SensorIDs      types.Set    `tfsdk:"sensor_ids"`
+	SensorFilter   types.String `tfsdk:"sensor_filter"`
+	PersonaID      types.String `tfsdk:"persona_id"`
+	MaxConcurrency types.Int32  `tfsdk:"max_concurrency"`
+	Results        types.Map    `tfsdk:"results"`
+
+	DefaultMetadata types.Map `tfsdk:"default_metadata"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+type SensorFleetPersonaResultModel struct {
+	PersonaID     types.String `tfsdk:"persona_id"`
+	PersonaStatus types.String `tfsdk:"persona_status"`
+	UpdatedAt     types.String `tfsdk:"updated_at"`
+	Error         types.String `tfsdk:"error"`
+}
+
+var sensorFleetPersonaResultType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"persona_id":     types.StringType,
+		"persona_status": types.StringType,
+		"updated_at":     types.StringType,
+		"error":          types.StringType,
+	},
+}
+
+func (r *SensorFleetPersonaResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_sensor_fleet_persona"
+}
+
+func (r *SensorFleetPersonaResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Sensor fleet persona resource is used to manage the persona deployed to many sensors at once, selected by ID or by a sensor search filter.
+
+Sensors are updated concurrently, up to ` + "`max_concurrency`" + ` at a time, and the result of each is reported in ` + "`results`" + `. Sensors that failed, drifted or newly match the filter are updated again on the next apply. Destroying the resource leaves the persona deployed to the sensors.`,
+		Attributes: map[string]schema.Attribute{
+			"sensor_ids": schema.SetAttribute{
+				MarkdownDescription: "UUIDs of the sensors.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"sensor_filter": schema.StringAttribute{
+				MarkdownDescription: "Sensor search filter, as for the `greynoise_sensor` data source, " +
+					"e.g. a public IP or name. Matching sensors are searched on every plan.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"persona_id": schema.StringAttribute{
+				MarkdownDescription: "Persona ID for sensor update.",
+				Required:            true,
+			},
+			"max_concurrency": schema.Int32Attribute{
+				MarkdownDescription: fmt.Sprintf("Maximum number of sensors updated concurrently. "+
+					"Defaults to `%d`.", defaultFleetConcurrency),
+				Optional: true,
+				Computed: true,
+				Default:  int32default.StaticInt32(defaultFleetConcurrency),
+				Validators: []validator.Int32{
+					int32validator.AtLeast(1),
+				},
+			},
+			"results": schema.MapNestedAttribute{
+				MarkdownDescription: "Result of the update of each sensor, by sensor UUID.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"persona_id": schema.StringAttribute{
+							MarkdownDescription: "Persona ID deployed to the sensor.",
+							Computed:            true,
+						},
+						"persona_status": schema.StringAttribute{
+							MarkdownDescription: fmt.Sprintf("Status of the persona on the sensor: `%s`, "+
+								"`%s` if it was not waited for, e.g. with `dry_run`, or `%s`.",
+								PersonaStatusApplied, PersonaStatusPending, PersonaStatusFailed),
+							Computed: true,
+						},
+						"updated_at": schema.StringAttribute{
+							MarkdownDescription: "Time the sensor was last updated, in RFC 3339 format. Updates " +
+								"fail with a conflict if the sensor was modified outside of Terraform since.",
+							Computed: true,
+						},
+						"error": schema.StringAttribute{
+							MarkdownDescription: "Error that occurred while updating the sensor, if it failed.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"default_metadata": schema.MapAttribute{
+				ElementType: types.StringType,
+				MarkdownDescription: "Provider `default_metadata` set on the sensors along with their updates, " +
+					"replacing existing values. The sensors are all updated again once it changes.",
+				Computed: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+			}),
+		},
+	}
+}
+
+func (r *SensorFleetPersonaResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("sensor_ids"),
+			path.MatchRoot("sensor_filter"),
+		),
+	}
+}
+
+func (r *SensorFleetPersonaResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*Data)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("expected *Data, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.data = data
+}
+
+func (r *SensorFleetPersonaResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SensorFleetPersonaResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	r.apply(ctx, &data, nil, true, &resp.Diagnostics)
+	if data.Results.IsUnknown() {
+		return
+	}
+
+	tflog.Trace(ctx, "Created sensor fleet persona resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SensorFleetPersonaResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SensorFleetPersonaResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	results, diags := fleetResults(ctx, data.Results)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var defaultMetadata map[string]string
+
+	if !data.DefaultMetadata.IsNull() {
+		resp.Diagnostics.Append(data.DefaultMetadata.ElementsAs(ctx, &defaultMetadata, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var mu sync.Mutex
+
+	errs := forEachSensor(ctx, sortedKeys(results), r.concurrency(data), func(ctx context.Context, id string) error {
+		sensor, err := r.data.Client.GetSensor(ctx, id)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if client.IsNotFound(err) {
+			resp.Diagnostics.AddWarning("Sensor not found",
+				fmt.Sprintf("Sensor %s was not found, it may have been deregistered outside of Terraform. "+
+					"Removing it from the results.", id))
+			delete(results, id)
+
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result := results[id]
+		// A pending persona may have been applied since.
+		if result.PersonaStatus.ValueString() == PersonaStatusPending && sensor.Persona == data.PersonaID.ValueString() {
+			result.PersonaStatus = types.StringValue(PersonaStatusApplied)
+		}
+
+		result.PersonaID = types.StringValue(sensor.Persona)
+		result.UpdatedAt = sensorUpdatedAt(sensor)
+		results[id] = result
+
+		// Only the default metadata set on every sensor is kept, so that the sensors are updated again otherwise.
+		for name, val := range defaultMetadata {
+			if v, ok := sensor.Metadata.Get(name); !ok || v != val {
+				delete(defaultMetadata, name)
+			}
+		}
+
+		return nil
+	})
+
+	for _, id := range sortedKeys(errs) {
+		addOperationError(&resp.Diagnostics, fmt.Sprintf("reading sensor %s", id), errs[id])
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Results, diags = types.MapValueFrom(ctx, sensorFleetPersonaResultType, results)
+	resp.Diagnostics.Append(diags...)
+
+	if defaultMetadata != nil {
+		data.DefaultMetadata, diags = types.MapValueFrom(ctx, types.StringType, defaultMetadata)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SensorFleetPersonaResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state SensorFleetPersonaResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	previous, diags := fleetResults(ctx, state.Results)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The persona is applied again to every sensor once the default metadata changes, to set it.
+	r.apply(ctx, &data, previous, state.DefaultMetadata.Equal(defaultMetadataValue(r.data)), &resp.Diagnostics)
+	if data.Results.IsUnknown() {
+		return
+	}
+
+	tflog.Trace(ctx, "Updated sensor fleet persona resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SensorFleetPersonaResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// The persona is left deployed to the sensors.
+}
+
+func (r *SensorFleetPersonaResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy or if the provider has not been configured.
+	if req.Plan.Raw.IsNull() || r.data == nil {
+		return
+	}
+
+	var data SensorFleetPersonaResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.PersonaID.IsUnknown() {
+		if err := checkPersona(ctx, r.data, data.PersonaID.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("persona_id"), "Persona not allowed", err.Error())
+
+			return
+		}
+	}
+
+	defaultMetadataChanged, diags := planDefaultMetadata(ctx, r.data, req.State, &resp.Plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Results are unknown on create or once the configuration changes.
+	if req.State.Raw.IsNull() || data.Results.IsUnknown() || data.PersonaID.IsUnknown() ||
+		data.SensorIDs.IsUnknown() || data.SensorFilter.IsUnknown() {
+		return
+	}
+
+	if defaultMetadataChanged {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("results"),
+			types.MapUnknown(sensorFleetPersonaResultType))...)
+
+		return
+	}
+
+	sensorIDs, diags := r.sensorIDs(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	results, diags := fleetResults(ctx, data.Results)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !slices.Equal(sensorIDs, sortedKeys(results)) || slices.ContainsFunc(sensorIDs, func(id string) bool {
+		return !resultApplied(results[id], data.PersonaID.ValueString())
+	}) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("results"),
+			types.MapUnknown(sensorFleetPersonaResultType))...)
+	}
+}
+
+// apply applies the persona to the selected sensors, along with the provider default metadata, skipping those
+// with the persona applied in the previous results with skipApplied, and sets the results. Sensors in the previous results are only updated if not modified
+// since. Each sensor that failed is reported in its own error, the results being saved still so that only
+// the failed sensors are updated again on the next apply.
+func (r *SensorFleetPersonaResource) apply(ctx context.Context, data *SensorFleetPersonaResourceModel,
+	previous map[string]SensorFleetPersonaResultModel, skipApplied bool, diags *diag.Diagnostics) {
+	sensorIDs, d := r.sensorIDs(ctx, *data)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	personaID := data.PersonaID.ValueString()
+	results := make(map[string]SensorFleetPersonaResultModel, len(sensorIDs))
+
+	var pending []string
+
+	for _, id := range sensorIDs {
+		if result, ok := previous[id]; ok && skipApplied && resultApplied(result, personaID) {
+			results[id] = result
+		} else {
+			pending = append(pending, id)
+		}
+	}
+
+	var mu sync.Mutex
+
+	errs := forEachSensor(ctx, pending, r.concurrency(*data), func(ctx context.Context, id string) error {
+		updatedAt := types.StringNull()
+		if result, ok := previous[id]; ok {
+			updatedAt = result.UpdatedAt
+		}
+
+		sensor, applied, err := applyPersona(ctx, r.data, id, personaID, r.data.DefaultMetadata, updatedAt)
+
+		result := SensorFleetPersonaResultModel{
+			PersonaID:     types.StringNull(),
+			PersonaStatus: types.StringValue(PersonaStatusFailed),
+			UpdatedAt:     types.StringNull(),
+			Error:         types.StringNull(),
+		}
+
+		switch {
+		case err != nil:
+			result.Error = types.StringValue(err.Error())
+		case applied:
+			result.PersonaStatus = types.StringValue(PersonaStatusApplied)
+		default:
+			result.PersonaStatus = types.StringValue(PersonaStatusPending)
+		}
+
+		if sensor != nil {
+			result.PersonaID = types.StringValue(sensor.Persona)
+			result.UpdatedAt = sensorUpdatedAt(sensor)
+		}
+
+		mu.Lock()
+		results[id] = result
+		mu.Unlock()
+
+		return err
+	})
+
+	for _, id := range sortedKeys(errs) {
+		addOperationError(diags, fmt.Sprintf("applying persona to sensor %s", id), errs[id])
+	}
+
+	tflog.Debug(ctx, "Applied persona to sensor fleet", map[string]interface{}{
+		"persona_id": personaID,
+		"sensors":    len(sensorIDs),
+		"updated":    len(pending),
+		"failed":     len(errs),
+	})
+
+	data.Results, d = types.MapValueFrom(ctx, sensorFleetPersonaResultType, results)
+	diags.Append(d...)
+
+	data.DefaultMetadata = defaultMetadataValue(r.data)
+}
+
+// sensorIDs returns the sorted IDs of the sensors selected, searching sensors matching the filter if set.
+func (r *SensorFleetPersonaResource) sensorIDs(ctx context.Context,
+	data SensorFleetPersonaResourceModel) ([]string, diag.Diagnostics) {
+	var (
+		ids   []string
+		diags diag.Diagnostics
+	)
+
+	if data.SensorFilter.IsNull() {
+		diags.Append(data.SensorIDs.ElementsAs(ctx, &ids, false)...)
+	} else {
+		var err error
+
+		ids, err = searchSensorIDs(ctx, r.data.Client, data.SensorFilter.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("sensor_filter"), "Sensor search error",
+				fmt.Sprintf("Error occurred while searching sensors: %s", err.Error()))
+		} else if len(ids) == 0 {
+			diags.AddAttributeWarning(path.Root("sensor_filter"), "No sensors matched",
+				fmt.Sprintf("No sensors match the filter %q, the persona is not applied to any sensor.",
+					data.SensorFilter.ValueString()))
+		}
+	}
+
+	slices.Sort(ids)
+
+	return ids, diags
+}
+
+func (r *SensorFleetPersonaResource) concurrency(data SensorFleetPersonaResourceModel) int {
+	if data.MaxConcurrency.ValueInt32() < 1 {
+		return defaultFleetConcurrency
+	}
+
+	return int(data.MaxConcurrency.ValueInt32())
+}
+
+// resultApplied returns whether the persona was applied to the sensor of the result.
+func resultApplied(result SensorFleetPersonaResultModel, personaID string) bool {
+	return result.PersonaStatus.ValueString() != PersonaStatusFailed && result.PersonaID.ValueString() == personaID
+}
+
+func fleetResults(ctx context.Context, m types.Map) (map[string]SensorFleetPersonaResultModel, diag.Diagnostics) {
+	results := map[string]SensorFleetPersonaResultModel{}
+	if m.IsNull() || m.IsUnknown() {
+		return results, nil
+	}
+
+	diags := m.ElementsAs(ctx, &results, false)
+
+	return results, diags
+}
+
+// searchSensorIDs returns the IDs of all the sensors matching the filter, across pages.
+func searchSensorIDs(ctx context.Context, c *client.GreyNoiseClient, filter string) ([]string, error) {
+	sensors, err := searchSensors(ctx, c, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(sensors))
+	for i, sensor := range sensors {
+		ids[i] = sensor.ID
+	}
+
+	return ids, nil
+}
+
+// searchSensors returns all the sensors matching the filter, across pages.
+func searchSensors(ctx context.Context, c *client.GreyNoiseClient, filter string) ([]client.Sensor, error) {
+	var sensors []client.Sensor
+
+	for page := int32(0); ; page++ {
+		result, err := c.SensorsSearch(ctx, client.SensorSearchFilter{
+			Filter:   filter,
+			Page:     page,
+			PageSize: sensorSearchPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sensors = append(sensors, result.Items...)
+
+		if len(result.Items) == 0 || len(sensors) >= int(result.Pagination.TotalItems) {
+			return sensors, nil
+		}
+	}
+}
+
+// forEachSensor calls fn for each sensor, with at most concurrency calls at a time, and returns the errors
+// by sensor ID. Sensors not started when the context is done fail with its error.
+func forEachSensor(ctx context.Context, ids []string, concurrency int,
+	fn func(ctx context.Context, id string) error) map[string]error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = map[string]error{}
+	)
+
+	sem := make(chan struct{}, concurrency)
+
+	for _, id := range ids {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			errs[id] = ctx.Err()
+			mu.Unlock()
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := fn(ctx, id); err != nil {
+				mu.Lock()
+				errs[id] = err
+				mu.Unlock()
+			}
+		}(id)
+	}
+
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,288 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+func TestAccSensorFleetPersonaResource(t *testing.T) {
+	t.Parallel()
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	sensorIDs := []string{
+		"0e6aed11-f2de-48f9-9526-8fb72be10700",
+		"1e6aed11-f2de-48f9-9526-8fb72be10700",
+		"2e6aed11-f2de-48f9-9526-8fb72be10700",
+		"3e6aed11-f2de-48f9-9526-8fb72be10700",
+		"4e6aed11-f2de-48f9-9526-8fb72be10700",
+		"2f6aed11-f2de-48f9-9526-8fb72be10700",
+	}
+	sensors := map[string]*client.Sensor{}
+	updates := map[string]*atomic.Int32{}
+
+	// The last sensors reject updates until reset.
+	rejectUpdates := map[string]*atomic.Bool{
+		sensorIDs[4]: {},
+		sensorIDs[5]: {},
+	}
+	for _, reject := range rejectUpdates {
+		reject.Store(true)
+	}
+
+	for i, id := range sensorIDs {
+		testSensor := &client.Sensor{
+			ID:   id,
+			Name: fmt.Sprintf("Fleet Sensor %d", i),
+			PublicIps: []string{
+				fmt.Sprintf("159.223.201.%d", i),
+			},
+			Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+			Status:    "healthy",
+			Disabled:  false,
+			LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+			CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+			UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+		}
+		sensors[id] = testSensor
+		updates[id] = &atomic.Int32{}
+
+		if reject, ok := rejectUpdates[id]; ok {
+			mockServer.RegisterMatch(http.MethodPut,
+				fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, id),
+				func(_ *url.URL) bool {
+					return reject.Load()
+				},
+				http.StatusBadRequest,
+				emptyBody,
+				nil,
+			)
+		}
+
+		mockServer.Register(http.MethodPut,
+			fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, id),
+			http.StatusAccepted,
+			emptyBody,
+			func(r *http.Request) {
+				updates[id].Add(1)
+
+				var req client.SensorUpdateRequest
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				testSensor.Persona = req.Persona
+			},
+		)
+		mockServer.Register(http.MethodGet,
+			fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, id),
+			http.StatusOK,
+			body(testSensor),
+			nil,
+		)
+	}
+
+	mockServer.RegisterMatch(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors", mockWorkspaceID),
+		func(url *url.URL) bool {
+			return url.Query().Get("filter") == "fleet"
+		},
+		http.StatusOK,
+		body(client.SensorSearchResponse{
+			Items: []client.Sensor{*sensors[sensorIDs[0]], *sensors[sensorIDs[1]], *sensors[sensorIDs[2]]},
+			Pagination: client.Pagination{
+				Page:       1,
+				PageSize:   100,
+				TotalItems: 3,
+			},
+		}),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	config := func(selector string) string {
+		return fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url = "%s"
+			  api_key  = "%s"
+			}
+
+			resource "greynoise_sensor_fleet_persona" "this" {
+			  %s
+			  persona_id      = "601c5e5a-cf2e-4401-844a-04d4391b1332"
+			  max_concurrency = 2
+			}
+			`, server.URL, mockAPIKey, selector)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: config(fmt.Sprintf(`sensor_ids = ["%s", "%s"]`, sensorIDs[0], sensorIDs[1])),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr("greynoise_sensor_fleet_persona.this", "results.%", "2"),
+						resource.TestCheckResourceAttr("greynoise_sensor_fleet_persona.this",
+							fmt.Sprintf("results.%s.persona_id", sensorIDs[0]), "601c5e5a-cf2e-4401-844a-04d4391b1332"),
+						resource.TestCheckResourceAttr("greynoise_sensor_fleet_persona.this",
+							fmt.Sprintf("results.%s.persona_status", sensorIDs[1]), "applied"),
+					),
+				},
+				// Sensors newly matching are updated, others are left untouched.
+				{
+					Config: config(`sensor_filter = "fleet"`),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr("greynoise_sensor_fleet_persona.this", "results.%", "3"),
+						resource.TestCheckResourceAttr("greynoise_sensor_fleet_persona.this",
+							fmt.Sprintf("results.%s.persona_status", sensorIDs[2]), "applied"),
+						func(_ *terraform.State) error {
+							for _, id := range sensorIDs[:3] {
+								if n := updates[id].Load(); n != 1 {
+									return fmt.Errorf("expected sensor %s to be updated once, got %d", id, n)
+								}
+							}
+
+							return nil
+						},
+					),
+				},
+			},
+		})
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: config(fmt.Sprintf(`sensor_ids = ["%s"]`, sensorIDs[3])),
+				},
+				// The failed sensor is reported as an error and saved in the results, without tainting.
+				{
+					Config:      config(fmt.Sprintf(`sensor_ids = ["%s", "%s"]`, sensorIDs[3], sensorIDs[4])),
+					ExpectError: regexp.MustCompile(`applying persona to sensor\s+4e6aed11-f2de-48f9-9526-8fb72be10700`),
+				},
+				{
+					RefreshState: true,
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr("greynoise_sensor_fleet_persona.this",
+							fmt.Sprintf("results.%s.persona_status", sensorIDs[3]), "applied"),
+						resource.TestCheckResourceAttr("greynoise_sensor_fleet_persona.this",
+							fmt.Sprintf("results.%s.persona_status", sensorIDs[4]), "failed"),
+						resource.TestMatchResourceAttr("greynoise_sensor_fleet_persona.this",
+							fmt.Sprintf("results.%s.error", sensorIDs[4]), regexp.MustCompile(`400`)),
+					),
+					ExpectNonEmptyPlan: true,
+				},
+				// Only the failed sensor is updated again.
+				{
+					PreConfig: func() {
+						rejectUpdates[sensorIDs[4]].Store(false)
+					},
+					Config: config(fmt.Sprintf(`sensor_ids = ["%s", "%s"]`, sensorIDs[3], sensorIDs[4])),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr("greynoise_sensor_fleet_persona.this",
+							fmt.Sprintf("results.%s.persona_status", sensorIDs[4]), "applied"),
+						func(_ *terraform.State) error {
+							for _, id := range sensorIDs[3:5] {
+								if n := updates[id].Load(); n != 1 {
+									return fmt.Errorf("expected sensor %s to be updated once, got %d", id, n)
+								}
+							}
+
+							return nil
+						},
+					),
+				},
+			},
+		})
+
+		assert.Equal(t, "601c5e5a-cf2e-4401-844a-04d4391b1332", sensors[sensorIDs[3]].Persona)
+		assert.Equal(t, "601c5e5a-cf2e-4401-844a-04d4391b1332", sensors[sensorIDs[4]].Persona)
+	})
+
+	t.Run("error - every sensor failed", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config:      config(fmt.Sprintf(`sensor_ids = ["%s"]`, sensorIDs[5])),
+					ExpectError: regexp.MustCompile(`applying persona to sensor\s+2f6aed11-f2de-48f9-9526-8fb72be10700`),
+				},
+			},
+		})
+	})
+
+	t.Run("error - sensor selector", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: config(fmt.Sprintf(`
+						sensor_ids    = ["%s"]
+						sensor_filter = "fleet"`, sensorIDs[0])),
+					ExpectError: regexp.MustCompile(`Exactly one of these attributes must be configured`),
+				},
+			},
+		})
+	})
+}
+
+func TestSensorFleetPersonaResource_SensorIDs(t *testing.T) {
+	t.Parallel()
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors", mockWorkspaceID),
+		http.StatusOK,
+		body(client.SensorSearchResponse{
+			Pagination: client.Pagination{
+				Page:     1,
+				PageSize: 100,
+			},
+		}),
+		nil,
+	)
+
+	server := mockServer.Server()
+	defer server.Close()
+
+	r := &SensorFleetPersonaResource{data: &Data{Client: testClient(t, server.URL)}}
+
+	ids, diags := r.sensorIDs(context.Background(), SensorFleetPersonaResourceModel{
+		SensorIDs:    types.SetNull(types.StringType),
+		SensorFilter: types.StringValue("decommissioned"),
+	})
+	assert.Empty(t, ids)
+	assert.Equal(t, diag.Diagnostics{
+		diag.NewAttributeWarningDiagnostic(path.Root("sensor_filter"), "No sensors matched",
+			`No sensors match the filter "decommissioned", the persona is not applied to any sensor.`),
+	}, diags)
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+// importSensor resolves the sensor identified on import by UUID, public IP or name, ignoring case.
+// Unlike search results, it fails unless exactly one sensor has the public IP or name.
+func importSensor(ctx context.Context, c *client.GreyNoiseClient, id string) (*client.Sensor, error) {
+	if _, err := uuid.Parse(id); err == nil {
+		sensor, err := c.GetSensor(ctx, id)
+		if client.IsNotFound(err) {
+			return nil, fmt.Errorf("no sensor with UUID %s", id)
+		}
+
+		return sensor, err
+	}
+
+	sensors, err := searchSensors(ctx, c, id)
+	if err != nil {
+		return nil, fmt.Errorf("error searching sensors: %w", err)
+	}
+
+	isIP := net.ParseIP(id) != nil
+
+	var matches []client.Sensor
+
+	for _, sensor := range sensors {
+		if isIP && slices.Contains(sensor.PublicIps, id) || !isIP && strings.EqualFold(sensor.Name, id) {
+			matches = append(matches, sensor)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no sensor with public IP or name %q", id)
+	case 1:
+		return &matches[0], nil
+	}
+
+	names := make([]string, len(matches))
+	for i, sensor := range matches {
+		names[i] = fmt.Sprintf("%s (%s)", sensor.ID, sensor.Name)
+	}
+
+	return nil, fmt.Errorf("%d sensors match %q, import by UUID instead: %s", len(matches), id,
+		strings.Join(names, ", "))
+}
+
+// importSensorState resolves the sensor identified on import and sets sensor_id, returning nil with an
+// error diagnostic if it could not be resolved.
+func importSensorState(ctx context.Context, d *Data, req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse) *client.Sensor {
+	sensor, err := importSensor(ctx, d.Client, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Import error",
+			fmt.Sprintf("Error occurred while importing sensor %q: %s", req.ID, err.Error()))
+
+		return nil
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sensor_id"), sensor.ID)...)
+
+	return sensor
+}
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+// mergeMetadata returns the default metadata of the provider overridden by the values of a resource.
+func mergeMetadata(defaults, values map[string]string) map[string]string {
+	merged := maps.Clone(defaults)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+
+	maps.Copy(merged, values)
+
+	return merged
+}
+
+// sensorMetadataUpdate returns the current metadata of a sensor with values set, replacing existing values,
+// or nil if nothing changes. Values of readonly or hidden metadata cannot be set and fail.
+func sensorMetadataUpdate(ctx context.Context, c *client.GreyNoiseClient, sensorID string,
+	values map[string]string) (*client.SensorMetadata, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	sensor, err := c.GetSensor(ctx, sensorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkMetadataWritable(sensor.Metadata, values); err != nil {
+		return nil, err
+	}
+
+	metadata := sensor.Metadata
+	changed := false
+
+	for _, name := range sortedKeys(values) {
+		if metadata.Set(name, values[name]) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil, nil
+	}
+
+	return &metadata, nil
+}
+
+// checkMetadataWritable returns an error if any of the values is of metadata of the sensor that is not
+// user modifiable.
+func checkMetadataWritable(metadata client.SensorMetadata, values map[string]string) error {
+	for _, name := range sortedKeys(values) {
+		if access, ok := metadata.Access(name); ok && access != client.MetadataAccessReadWrite {
+			return fmt.Errorf("metadata %s of the sensor is %s and cannot be set", name, access)
+		}
+	}
+
+	return nil
+}
+
+// defaultMetadataAttribute returns the attribute showing in plan the provider default metadata set on
+// the sensors updated by a resource.
+func defaultMetadataAttribute() schema.MapAttribute {
+	return schema.MapAttribute{
+		ElementType: types.StringType,
+		MarkdownDescription: "Provider `default_metadata` set on the sensor along with its updates, replacing " +
+			"existing values. The sensor is updated again once it changes.",
+		Computed: true,
+	}
+}
+
+// defaultMetadataValue returns the provider default metadata as the value of the default_metadata attribute.
+func defaultMetadataValue(d *Data) types.Map {
+	if len(d.DefaultMetadata) == 0 {
+		return types.MapNull(types.StringType)
+	}
+
+	elements := make(map[string]attr.Value, len(d.DefaultMetadata))
+	for name, val := range d.DefaultMetadata {
+		elements[name] = types.StringValue(val)
+	}
+
+	return types.MapValueMust(types.StringType, elements)
+}
+
+// planDefaultMetadata sets default_metadata in the plan to the provider default metadata, and returns
+// whether it differs from the state, e.g. once the defaults change or the sensor metadata was modified
+// outside of Terraform, in which case the sensor is updated to set it.
+func planDefaultMetadata(ctx context.Context, d *Data, state tfsdk.State, plan *tfsdk.Plan) (bool, diag.Diagnostics) {
+	defaultMetadata := defaultMetadataValue(d)
+
+	diags := plan.SetAttribute(ctx, path.Root("default_metadata"), defaultMetadata)
+	if diags.HasError() || state.Raw.IsNull() {
+		return false, diags
+	}
+
+	var stateDefaultMetadata types.Map
+
+	diags.Append(state.GetAttribute(ctx, path.Root("default_metadata"), &stateDefaultMetadata)...)
+
+	return !defaultMetadata.Equal(stateDefaultMetadata), diags
+}
+
+// refreshMetadata returns the values of the keys of m from the metadata of a sensor, dropping keys
+// no longer set.
+func refreshMetadata(ctx context.Context, m types.Map, metadata client.SensorMetadata) (types.Map, diag.Diagnostics) {
+	if m.IsNull() || m.IsUnknown() {
+		return m, nil
+	}
+
+	var values map[string]string
+
+	diags := m.ElementsAs(ctx, &values, false)
+	if diags.HasError() {
+		return m, diags
+	}
+
+	for name := range values {
+		if val, ok := metadata.Get(name); ok {
+			values[name] = val
+		} else {
+			delete(values, name)
+		}
+	}
+
+	return types.MapValueFrom(ctx, types.StringType, values)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -17,6 +19,7 @@ import (
 
 var _ resource.Resource = &SensorMetadataResource{}
 var _ resource.ResourceWithImportState = &SensorMetadataResource{}
+var _ resource.ResourceWithModifyPlan = &SensorMetadataResource{}
 
 func NewSensorMetadataResource() resource.Resource {
 	return &SensorMetadataResource{}
@@ -27,15 +30,20 @@ type SensorMetadataResource struct {
 }
 
 type SensorMetadataResourceModel struct {
-	SensorID types.String `tfsdk:"sensor_id"`
-	Name     types.String `tfsdk:"name"`
+	SensorID    types.String `tfsdk:"sensor_id"`
+	Name        types.String `tfsdk:"name"`
+	Metadata    types.Map    `tfsdk:"metadata"`
+	MetadataAll types.Map    `tfsdk:"metadata_all"`
+	UpdatedAt   types.String `tfsdk:"updated_at"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *SensorMetadataResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_sensor_metadata"
 }
 
-func (r *SensorMetadataResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *SensorMetadataResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: `Sensor metadata resource is used to manage metadata about a sensor.`,
 		Attributes: map[string]schema.Attribute{
@@ -50,6 +58,30 @@ func (r *SensorMetadataResource) Schema(_ context.Context, _ resource.SchemaRequ
 				MarkdownDescription: "Name of the sensor.",
 				Required:            true,
 			},
+			"metadata": schema.MapAttribute{
+				ElementType: types.StringType,
+				MarkdownDescription: "Metadata of the sensor, overriding the provider `default_metadata`. " +
+					"Other metadata of the sensor is left unchanged.",
+				Optional: true,
+			},
+			"metadata_all": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Metadata of the sensor, including the provider `default_metadata`.",
+				Computed:            true,
+			},
+			"updated_at": schema.StringAttribute{
+				MarkdownDescription: "Time the sensor was last updated, in RFC 3339 format. Updates fail " +
+					"with a conflict if the sensor was modified outside of Terraform since.",
+				Computed: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -82,13 +114,25 @@ func (r *SensorMetadataResource) Create(ctx context.Context, req resource.Create
 		return
 	}
 
-	if err := r.data.Client.UpdateSensor(ctx, data.SensorID.ValueString(), client.SensorUpdateRequest{
-		Name: data.Name.ValueString(),
-	}); err != nil {
-		resp.Diagnostics.AddError(
-			"Operation error",
-			fmt.Sprintf("Error occurred while updating sensor metadata: %s", err.Error()),
-		)
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	var metadataAll map[string]string
+	if !data.MetadataAll.IsNull() {
+		resp.Diagnostics.Append(data.MetadataAll.ElementsAs(ctx, &metadataAll, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if err := r.update(ctx, &data, metadataAll, types.StringNull()); err != nil {
+		addOperationError(&resp.Diagnostics, "updating sensor metadata", err)
 
 		return
 	}
@@ -106,7 +150,19 @@ func (r *SensorMetadataResource) Read(ctx context.Context, req resource.ReadRequ
 		return
 	}
 
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
 	sensor, err := r.data.Client.GetSensor(ctx, data.SensorID.ValueString())
+	if removeNotFoundSensor(ctx, resp, data.SensorID.ValueString(), err) {
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Sensor error",
@@ -117,6 +173,17 @@ func (r *SensorMetadataResource) Read(ctx context.Context, req resource.ReadRequ
 	}
 
 	data.Name = types.StringValue(sensor.Name)
+	data.UpdatedAt = sensorUpdatedAt(sensor)
+
+	data.Metadata, diags = refreshMetadata(ctx, data.Metadata, sensor.Metadata)
+	resp.Diagnostics.Append(diags...)
+
+	data.MetadataAll, diags = refreshMetadata(ctx, data.MetadataAll, sensor.Metadata)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -130,13 +197,32 @@ func (r *SensorMetadataResource) Update(ctx context.Context, req resource.Update
 		return
 	}
 
-	if err := r.data.Client.UpdateSensor(ctx, data.SensorID.ValueString(), client.SensorUpdateRequest{
-		Name: data.Name.ValueString(),
-	}); err != nil {
-		resp.Diagnostics.AddError(
-			"Operation error",
-			fmt.Sprintf("Error occurred while updating sensor metadata: %s", err.Error()),
-		)
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	var metadataAll map[string]string
+	if !data.MetadataAll.IsNull() {
+		resp.Diagnostics.Append(data.MetadataAll.ElementsAs(ctx, &metadataAll, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var updatedAt types.String
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("updated_at"), &updatedAt)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.update(ctx, &data, metadataAll, updatedAt); err != nil {
+		addOperationError(&resp.Diagnostics, "updating sensor metadata", err)
 
 		return
 	}
@@ -155,6 +241,110 @@ func (r *SensorMetadataResource) Delete(ctx context.Context, req resource.Delete
 	}
 }
 
+func (r *SensorMetadataResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to merge on destroy or if the provider has not been configured.
+	if req.Plan.Raw.IsNull() || r.data == nil {
+		return
+	}
+
+	var metadata map[string]types.String
+
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("metadata"), &metadata)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	values := map[string]string{}
+	for name, val := range metadata {
+		if val.IsUnknown() {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("metadata_all"),
+				types.MapUnknown(types.StringType))...)
+
+			return
+		}
+
+		values[name] = val.ValueString()
+	}
+
+	merged := mergeMetadata(r.data.DefaultMetadata, values)
+
+	metadataAll := types.MapNull(types.StringType)
+	if len(merged) != 0 {
+		var diags diag.Diagnostics
+
+		metadataAll, diags = types.MapValueFrom(ctx, types.StringType, merged)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("metadata_all"), metadataAll)...)
+
+	var sensorID types.String
+
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("sensor_id"), &sensorID)...)
+	if resp.Diagnostics.HasError() || sensorID.IsUnknown() || len(merged) == 0 {
+		return
+	}
+
+	// Which metadata is readonly depends on the sensor, so it is only checked once the sensor is known.
+	// Errors getting the sensor are left to the apply to report.
+	sensor, err := r.data.Client.GetSensor(ctx, sensorID.ValueString())
+	if err != nil {
+		return
+	}
+
+	if err := checkMetadataWritable(sensor.Metadata, merged); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("metadata"), "Metadata not writable", err.Error())
+	}
+}
+
 func (r *SensorMetadataResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("sensor_id"), req, resp)
+	sensor := importSensorState(ctx, r.data, req, resp)
+	if sensor == nil {
+		return
+	}
+
+	// The metadata managed is unknown until configured.
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), sensor.Name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("updated_at"), sensorUpdatedAt(sensor))...)
+}
+
+// update updates the name of the sensor and sets its metadata, keeping other metadata, and waits for
+// the name to be applied. If updatedAt is known, the sensor is only updated if not modified since.
+func (r *SensorMetadataResource) update(ctx context.Context, data *SensorMetadataResourceModel,
+	values map[string]string, updatedAt types.String) error {
+	unlock, err := r.data.Client.LockSensor(ctx, data.SensorID.ValueString())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	metadata, err := sensorMetadataUpdate(ctx, r.data.Client, data.SensorID.ValueString(), values)
+	if err != nil {
+		return err
+	}
+
+	if err := updateSensor(ctx, r.data.Client, data.SensorID.ValueString(), client.SensorUpdateRequest{
+		Name:     data.Name.ValueString(),
+		Metadata: metadata,
+	}, updatedAt); err != nil {
+		return err
+	}
+
+	var sensor *client.Sensor
+
+	if r.data.Client.DryRun() {
+		sensor, err = r.data.Client.GetSensor(ctx, data.SensorID.ValueString())
+	} else {
+		// Changes are applied asynchronously, wait for them so that the next refresh does not report drift.
+		sensor, err = r.data.Client.WaitForSensor(ctx, data.SensorID.ValueString(), func(sensor *client.Sensor) bool {
+			return sensor.Name == data.Name.ValueString()
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	data.UpdatedAt = sensorUpdatedAt(sensor)
+
+	return nil
 }
@@ -9,7 +9,10 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 
 	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
 )
@@ -171,3 +174,217 @@ func TestAccSensorMetadataResource(t *testing.T) {
 		})
 	}
 }
+
+func TestAccSensorMetadataResource_DefaultMetadata(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "4d6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Gifted Trout",
+		PublicIps: []string{
+			"159.223.200.220",
+		},
+		Persona: "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Metadata: client.SensorMetadata{
+			Items: []client.SensorMetadatum{
+				{
+					Access: client.MetadataAccessReadonly,
+					Name:   "provider",
+					Val:    "greynoise",
+				},
+				{
+					Access: client.MetadataAccessReadWrite,
+					Name:   "location",
+					Val:    "nyc",
+				},
+			},
+		},
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+	}
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	mockServer.Register(http.MethodPut,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusAccepted,
+		emptyBody,
+		func(r *http.Request) {
+			var req client.SensorUpdateRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+
+			if req.Name != "" {
+				testSensor.Name = req.Name
+			}
+
+			if req.Metadata != nil {
+				testSensor.Metadata = *req.Metadata
+			}
+		},
+	)
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusOK,
+		body(testSensor),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	config := func(managedBy string) string {
+		return fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url = "%s"
+			  api_key  = "%s"
+
+			  default_metadata = {
+			    managed_by = "%s"
+			    team       = "blue"
+			  }
+			}
+
+			resource "greynoise_sensor_metadata" "this" {
+			  sensor_id = "4d6aed11-f2de-48f9-9526-8fb72be10700"
+			  name      = "Angry Cuscus"
+
+			  metadata = {
+			    team = "red"
+			    repo = "infrastructure"
+			  }
+			}
+			`, server.URL, mockAPIKey, managedBy)
+	}
+
+	checkSensorMetadata := func(managedBy string) resource.TestCheckFunc {
+		return func(_ *terraform.State) error {
+			want := map[string]string{
+				"provider":   "greynoise",
+				"location":   "nyc",
+				"managed_by": managedBy,
+				"team":       "red",
+				"repo":       "infrastructure",
+			}
+
+			if len(testSensor.Metadata.Items) != len(want) {
+				return fmt.Errorf("expected %d sensor metadata, got: %v", len(want), testSensor.Metadata.Items)
+			}
+
+			for name, val := range want {
+				if actual, _ := testSensor.Metadata.Get(name); actual != val {
+					return fmt.Errorf("expected sensor metadata %s to be %q, got: %q", name, val, actual)
+				}
+			}
+
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("terraform"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectKnownValue("greynoise_sensor_metadata.this",
+							tfjsonpath.New("metadata_all"),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"managed_by": knownvalue.StringExact("terraform"),
+								"team":       knownvalue.StringExact("red"),
+								"repo":       knownvalue.StringExact("infrastructure"),
+							}),
+						),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_metadata.this", "metadata.%", "2"),
+					resource.TestCheckResourceAttr("greynoise_sensor_metadata.this", "metadata_all.%", "3"),
+					checkSensorMetadata("terraform"),
+				),
+			},
+			{
+				Config: config("platform"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("greynoise_sensor_metadata.this",
+							plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("greynoise_sensor_metadata.this",
+							tfjsonpath.New("metadata_all").AtMapKey("managed_by"),
+							knownvalue.StringExact("platform"),
+						),
+					},
+				},
+				Check: checkSensorMetadata("platform"),
+			},
+		},
+	})
+}
+
+func TestAccSensorMetadataResource_ReadonlyMetadata(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "5f6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Quiet Heron",
+		PublicIps: []string{
+			"159.223.200.223",
+		},
+		Persona: "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Metadata: client.SensorMetadata{
+			Items: []client.SensorMetadatum{
+				{
+					Access: client.MetadataAccessReadonly,
+					Name:   "provider",
+					Val:    "greynoise",
+				},
+			},
+		},
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+	}
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusOK,
+		body(testSensor),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "greynoise" {
+					  base_url = "%s"
+					  api_key  = "%s"
+					}
+
+					resource "greynoise_sensor_metadata" "this" {
+					  sensor_id = "5f6aed11-f2de-48f9-9526-8fb72be10700"
+					  name      = "Quiet Heron"
+
+					  metadata = {
+					    provider = "terraform"
+					  }
+					}
+					`, server.URL, mockAPIKey),
+				ExpectError: regexp.MustCompile(`(?s)Metadata not writable.*metadata provider of the sensor is\s+readonly`),
+			},
+		},
+	})
+}
@@ -3,12 +3,18 @@ package provider
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
@@ -17,6 +23,8 @@ import (
 
 var _ resource.Resource = &SensorPersonaResource{}
 var _ resource.ResourceWithImportState = &SensorPersonaResource{}
+var _ resource.ResourceWithModifyPlan = &SensorPersonaResource{}
+var _ resource.ResourceWithValidateConfig = &SensorPersonaResource{}
 
 func NewSensorPersonaResource() resource.Resource {
 	return &SensorPersonaResource{}
@@ -26,22 +34,68 @@ type SensorPersonaResource struct {
 	data *Data
 }
 
+const (
+	PersonaStatusApplied = "applied"
+	PersonaStatusPending = "pending"
+
+	OnDestroyRetain  = "retain"
+	OnDestroyRestore = "restore"
+	OnDestroyDefault = "default"
+)
+
+var onDestroyModes = []string{OnDestroyRetain, OnDestroyRestore, OnDestroyDefault}
+
 type SensorPersonaResourceModel struct {
-	PersonaID types.String `tfsdk:"persona_id"`
-	SensorID  types.String `tfsdk:"sensor_id"`
+	PersonaID     types.String              `tfsdk:"persona_id"`
+	PersonaName   types.String              `tfsdk:"persona_name"`
+	PersonaSearch *SensorPersonaSearchModel `tfsdk:"persona_search"`
+	SensorID      types.String              `tfsdk:"sensor_id"`
+	PersonaStatus types.String              `tfsdk:"persona_status"`
+	AppliedAt     types.String              `tfsdk:"applied_at"`
+	UpdatedAt     types.String              `tfsdk:"updated_at"`
+
+	OnDestroy          types.String `tfsdk:"on_destroy"`
+	OnDestroyPersonaID types.String `tfsdk:"on_destroy_persona_id"`
+	OriginalPersonaID  types.String `tfsdk:"original_persona_id"`
+
+	DefaultMetadata types.Map `tfsdk:"default_metadata"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+type SensorPersonaSearchModel struct {
+	Search   types.String `tfsdk:"search"`
+	Tier     types.String `tfsdk:"tier"`
+	Category types.String `tfsdk:"category"`
+	Protocol types.String `tfsdk:"protocol"`
+}
+
+// isKnown returns whether all the filters of the search are known.
+func (m *SensorPersonaSearchModel) isKnown() bool {
+	return !m.Search.IsUnknown() && !m.Tier.IsUnknown() && !m.Category.IsUnknown() && !m.Protocol.IsUnknown()
 }
 
 func (r *SensorPersonaResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_sensor_persona"
 }
 
-func (r *SensorPersonaResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *SensorPersonaResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: `Sensor persona resource is used to manage the persona deployed to a sensor.`,
+		MarkdownDescription: `Sensor persona resource is used to manage the persona deployed to a sensor.
+
+The persona is either set by ID with ` + "`persona_id`" + `, or resolved during plan by name with ` + "`persona_name`" + ` or by search with the ` + "`persona_search`" + ` block, which must match exactly one persona.
+
+Personas are applied to sensors asynchronously, so the resource waits for the sensor to run the persona, up to the ` + "`create`" + ` and ` + "`update`" + ` timeouts.`,
 		Attributes: map[string]schema.Attribute{
 			"persona_id": schema.StringAttribute{
-				MarkdownDescription: "Persona ID for sensor update.",
-				Required:            true,
+				MarkdownDescription: "Persona ID for sensor update. Resolved from `persona_name` or " +
+					"`persona_search` if set.",
+				Optional: true,
+				Computed: true,
+			},
+			"persona_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the persona, ignoring case. Exactly one persona must have the name.",
+				Optional:            true,
 			},
 			"sensor_id": schema.StringAttribute{
 				MarkdownDescription: "UUID of the sensor.",
@@ -50,6 +104,76 @@ func (r *SensorPersonaResource) Schema(_ context.Context, _ resource.SchemaReque
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"persona_status": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Status of the persona on the sensor: `%s` once the sensor "+
+					"runs it, as personas are applied asynchronously, or `%s` if it was not waited for, "+
+					"e.g. with `dry_run`.", PersonaStatusApplied, PersonaStatusPending),
+				Computed: true,
+			},
+			"applied_at": schema.StringAttribute{
+				MarkdownDescription: "Time the persona was applied to the sensor, in RFC 3339 format.",
+				Computed:            true,
+			},
+			"updated_at": schema.StringAttribute{
+				MarkdownDescription: "Time the sensor was last updated, in RFC 3339 format. Updates fail " +
+					"with a conflict if the sensor was modified outside of Terraform since.",
+				Computed: true,
+			},
+			"on_destroy": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Persona of the sensor on destroy: `%s` leaves the persona "+
+					"deployed, `%s` applies `original_persona_id` back and `%s` applies `on_destroy_persona_id`. "+
+					"Defaults to `%s`.", OnDestroyRetain, OnDestroyRestore, OnDestroyDefault, OnDestroyRetain),
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(OnDestroyRetain),
+				Validators: []validator.String{
+					stringvalidator.OneOf(onDestroyModes...),
+				},
+			},
+			"on_destroy_persona_id": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Persona ID applied on destroy with `on_destroy = \"%s\"`.",
+					OnDestroyDefault),
+				Optional: true,
+			},
+			"original_persona_id": schema.StringAttribute{
+				MarkdownDescription: "Persona ID of the sensor before the resource was created, " +
+					"applied back on destroy with `on_destroy = \"restore\"`, even if not allowed by the persona policy.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"default_metadata": defaultMetadataAttribute(),
+		},
+		Blocks: map[string]schema.Block{
+			"persona_search": schema.SingleNestedBlock{
+				MarkdownDescription: "Filters of the persona, as for the `greynoise_personas` data source. " +
+					"Exactly one persona must match.",
+				Attributes: map[string]schema.Attribute{
+					"search": schema.StringAttribute{
+						MarkdownDescription: "Partial text search on persona name.",
+						Optional:            true,
+					},
+					"tier": schema.StringAttribute{
+						MarkdownDescription: "Tier of persona.",
+						Optional:            true,
+					},
+					"category": schema.StringAttribute{
+						MarkdownDescription: "Category of persona.",
+						Optional:            true,
+					},
+					"protocol": schema.StringAttribute{
+						MarkdownDescription: "Protocol of persona.",
+						Optional:            true,
+					},
+				},
+			},
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -82,13 +206,27 @@ func (r *SensorPersonaResource) Create(ctx context.Context, req resource.CreateR
 		return
 	}
 
-	if err := r.data.Client.UpdateSensor(ctx, data.SensorID.ValueString(), client.SensorUpdateRequest{
-		Persona: data.PersonaID.ValueString(),
-	}); err != nil {
-		resp.Diagnostics.AddError(
-			"Operation error",
-			fmt.Sprintf("Error occurred while applying persona to sensor: %s", err.Error()),
-		)
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	// Recorded to be restored on destroy.
+	sensor, err := r.data.Client.GetSensor(ctx, data.SensorID.ValueString())
+	if err != nil {
+		addOperationError(&resp.Diagnostics, "getting sensor", err)
+
+		return
+	}
+
+	data.OriginalPersonaID = types.StringValue(sensor.Persona)
+
+	if err := r.update(ctx, &data, types.StringNull()); err != nil {
+		addOperationError(&resp.Diagnostics, "applying persona to sensor", err)
 
 		return
 	}
@@ -106,7 +244,19 @@ func (r *SensorPersonaResource) Read(ctx context.Context, req resource.ReadReque
 		return
 	}
 
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
 	sensor, err := r.data.Client.GetSensor(ctx, data.SensorID.ValueString())
+	if removeNotFoundSensor(ctx, resp, data.SensorID.ValueString(), err) {
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Sensor error",
@@ -115,7 +265,21 @@ func (r *SensorPersonaResource) Read(ctx context.Context, req resource.ReadReque
 
 		return
 	}
+	// A pending persona may have been applied since, and nothing is known of imported ones.
+	if data.PersonaStatus.ValueString() != PersonaStatusApplied &&
+		(data.PersonaID.IsNull() || sensor.Persona == data.PersonaID.ValueString()) {
+		data.PersonaStatus = types.StringValue(PersonaStatusApplied)
+		data.AppliedAt = types.StringValue(sensor.UpdatedAt.Format(time.RFC3339))
+	}
+
 	data.PersonaID = types.StringValue(sensor.Persona)
+	data.UpdatedAt = sensorUpdatedAt(sensor)
+
+	data.DefaultMetadata, diags = refreshMetadata(ctx, data.DefaultMetadata, sensor.Metadata)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -129,13 +293,24 @@ func (r *SensorPersonaResource) Update(ctx context.Context, req resource.UpdateR
 		return
 	}
 
-	if err := r.data.Client.UpdateSensor(ctx, data.SensorID.ValueString(), client.SensorUpdateRequest{
-		Persona: data.PersonaID.ValueString(),
-	}); err != nil {
-		resp.Diagnostics.AddError(
-			"Operation error",
-			fmt.Sprintf("Error occurred while applying persona to sensor: %s", err.Error()),
-		)
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	var updatedAt types.String
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("updated_at"), &updatedAt)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.update(ctx, &data, updatedAt); err != nil {
+		addOperationError(&resp.Diagnostics, "applying persona to sensor", err)
 
 		return
 	}
@@ -152,8 +327,223 @@ func (r *SensorPersonaResource) Delete(ctx context.Context, req resource.DeleteR
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	var personaID types.String
+
+	apply := applyPersona
+
+	switch data.OnDestroy.ValueString() {
+	case OnDestroyRestore:
+		personaID = data.OriginalPersonaID
+		// The original persona is restored even if the persona policy does not allow it.
+		apply = setPersona
+	case OnDestroyDefault:
+		personaID = data.OnDestroyPersonaID
+	default:
+		return
+	}
+
+	if personaID.ValueString() == "" {
+		resp.Diagnostics.AddWarning("No persona to apply on destroy",
+			fmt.Sprintf("The persona of sensor %s is unknown, e.g. as the resource was imported, "+
+				"so the current persona is retained.", data.SensorID.ValueString()))
+
+		return
+	}
+
+	deleteTimeout, diags := data.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	// The default metadata is only set along with the updates planned.
+	if _, _, err := apply(ctx, r.data, data.SensorID.ValueString(), personaID.ValueString(), nil,
+		types.StringNull()); err != nil {
+		addOperationError(&resp.Diagnostics, "applying persona to sensor on destroy", err)
+
+		return
+	}
+
+	tflog.Trace(ctx, "Deleted sensor persona resource", map[string]interface{}{
+		"persona_id": personaID.ValueString(),
+	})
+}
+
+func (r *SensorPersonaResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data SensorPersonaResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	personaSet := 0
+	for _, set := range []bool{!data.PersonaID.IsNull(), !data.PersonaName.IsNull(), data.PersonaSearch != nil} {
+		if set {
+			personaSet++
+		}
+	}
+
+	if personaSet != 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("persona_id"), "Invalid persona",
+			"Exactly one of persona_id, persona_name or persona_search must be set.")
+	}
+
+	if data.OnDestroy.IsUnknown() || data.OnDestroyPersonaID.IsUnknown() {
+		return
+	}
+
+	isDefault := data.OnDestroy.ValueString() == OnDestroyDefault
+
+	if isDefault && data.OnDestroyPersonaID.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("on_destroy_persona_id"), "Missing persona on destroy",
+			fmt.Sprintf("on_destroy_persona_id is required with on_destroy = %q.", OnDestroyDefault))
+	}
+
+	if !isDefault && !data.OnDestroyPersonaID.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("on_destroy_persona_id"), "Invalid persona on destroy",
+			fmt.Sprintf("on_destroy_persona_id can only be used with on_destroy = %q.", OnDestroyDefault))
+	}
+}
+
+func (r *SensorPersonaResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy or if the provider has not been configured.
+	if req.Plan.Raw.IsNull() || r.data == nil {
+		return
+	}
+
+	var data SensorPersonaResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	personaID := r.resolvePersona(ctx, data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("persona_id"), personaID)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	defaultMetadataChanged, diags := planDefaultMetadata(ctx, r.data, req.State, &resp.Plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The persona resolved may differ from the one on the sensor without any change to the configuration.
+	if !req.State.Raw.IsNull() {
+		var statePersonaID types.String
+
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("persona_id"), &statePersonaID)...)
+		if !personaID.Equal(statePersonaID) || defaultMetadataChanged {
+			resp.Diagnostics.Append(planPersonaUpdate(ctx, &resp.Plan)...)
+		}
+	}
+
+	if resp.Diagnostics.HasError() || personaID.IsUnknown() || r.data.PersonaPolicy.IsEmpty() {
+		return
+	}
+
+	if err := checkPersona(ctx, r.data, personaID.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("persona_id"), "Persona not allowed", err.Error())
+	}
+}
+
+// resolvePersona returns the persona ID of the configuration, looking up the persona by name or search
+// if set. The persona ID is unknown until the name or search is.
+func (r *SensorPersonaResource) resolvePersona(ctx context.Context, data SensorPersonaResourceModel,
+	diags *diag.Diagnostics) types.String {
+	var (
+		persona *client.Persona
+		err     error
+		attr    path.Path
+	)
+
+	switch {
+	case !data.PersonaName.IsNull():
+		if data.PersonaName.IsUnknown() {
+			return types.StringUnknown()
+		}
+
+		attr = path.Root("persona_name")
+		persona, err = lookupPersonaByName(ctx, r.data.Client, data.PersonaName.ValueString())
+	case data.PersonaSearch != nil:
+		if !data.PersonaSearch.isKnown() {
+			return types.StringUnknown()
+		}
+
+		attr = path.Root("persona_search")
+		persona, err = lookupPersonaBySearch(ctx, r.data.Client, client.PersonaSearchFilters{
+			Search:     data.PersonaSearch.Search.ValueString(),
+			Tiers:      data.PersonaSearch.Tier.ValueString(),
+			Categories: data.PersonaSearch.Category.ValueString(),
+			Protocols:  data.PersonaSearch.Protocol.ValueString(),
+		})
+	default:
+		return data.PersonaID
+	}
+
+	if err != nil {
+		diags.AddAttributeError(attr, "Persona lookup error", err.Error())
+
+		return types.StringUnknown()
+	}
+
+	tflog.Debug(ctx, "Resolved persona", map[string]interface{}{
+		"persona_id":   persona.ID,
+		"persona_name": persona.Name,
+	})
+
+	return types.StringValue(persona.ID)
 }
 
 func (r *SensorPersonaResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("sensor_id"), req, resp)
+	sensor := importSensorState(ctx, r.data, req, resp)
+	if sensor == nil {
+		return
+	}
+
+	// The persona the sensor had before it was managed by Terraform is unknown.
+	for name, value := range map[string]string{
+		"persona_id":     sensor.Persona,
+		"persona_status": PersonaStatusApplied,
+		"applied_at":     sensor.UpdatedAt.Format(time.RFC3339),
+		"updated_at":     sensorUpdatedAt(sensor).ValueString(),
+		"on_destroy":     OnDestroyRetain,
+	} {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(name), value)...)
+	}
+}
+
+// update applies the persona to the sensor and sets the computed attributes. If updatedAt is known, the
+// sensor is only updated if not modified since.
+func (r *SensorPersonaResource) update(ctx context.Context, data *SensorPersonaResourceModel,
+	updatedAt types.String) error {
+	sensor, applied, err := applyPersona(ctx, r.data, data.SensorID.ValueString(), data.PersonaID.ValueString(),
+		r.data.DefaultMetadata, updatedAt)
+	if err != nil {
+		return err
+	}
+
+	if applied {
+		data.PersonaStatus = types.StringValue(PersonaStatusApplied)
+		data.AppliedAt = types.StringValue(sensor.UpdatedAt.Format(time.RFC3339))
+	} else {
+		data.PersonaStatus = types.StringValue(PersonaStatusPending)
+		data.AppliedAt = types.StringNull()
+	}
+
+	data.UpdatedAt = sensorUpdatedAt(sensor)
+	data.DefaultMetadata = defaultMetadataValue(r.data)
+
+	return nil
 }
@@ -4,12 +4,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
 )
@@ -52,6 +62,15 @@ func TestAccSensorPersonaResource(t *testing.T) {
 		nil,
 	)
 
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		mockServer.Register(method,
+			fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, "9d6aed11-f2de-48f9-9526-8fb72be10700"),
+			http.StatusUnauthorized,
+			emptyBody,
+			nil,
+		)
+	}
+
 	server := mockServer.Server()
 
 	type step struct {
@@ -81,10 +100,44 @@ func TestAccSensorPersonaResource(t *testing.T) {
 						resource.TestCheckResourceAttr("greynoise_sensor_persona.this", "persona_id",
 							"501c5e5a-cf2e-4401-844a-04d4391b1332",
 						),
+						resource.TestCheckResourceAttr("greynoise_sensor_persona.this", "persona_status",
+							"applied",
+						),
+						resource.TestCheckResourceAttr("greynoise_sensor_persona.this", "applied_at",
+							"2024-08-26T13:53:07Z",
+						),
 					),
 				},
 			},
 		},
+		{
+			name: "error - create timeout",
+			steps: []step{
+				{
+					config: `resource "greynoise_sensor_persona" "this" {
+						  sensor_id = "1d6aed11-f2de-48f9-9526-8fb72be10700"
+						  persona_id = "501c5e5a-cf2e-4401-844a-04d4391b1332"
+
+						  timeouts {
+						    create = "1ns"
+						  }
+						}`,
+					expectError: regexp.MustCompile(`context deadline exceeded`),
+				},
+			},
+		},
+		{
+			name: "error - unauthorized",
+			steps: []step{
+				{
+					config: `resource "greynoise_sensor_persona" "this" {
+						  sensor_id = "9d6aed11-f2de-48f9-9526-8fb72be10700"
+						  persona_id = "501c5e5a-cf2e-4401-844a-04d4391b1332"
+						}`,
+					expectError: regexp.MustCompile(`Unauthorized`),
+				},
+			},
+		},
 		{
 			name: "success - update plan",
 			steps: []step{
@@ -156,3 +209,1073 @@ func TestAccSensorPersonaResource(t *testing.T) {
 		})
 	}
 }
+
+func TestAccSensorPersonaResource_SafetyModes(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "2d6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Patient Heron",
+		PublicIps: []string{
+			"159.223.200.218",
+		},
+		Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+	}
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	var updates atomic.Int32
+
+	mockServer.Register(http.MethodPut,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusAccepted,
+		emptyBody,
+		func(_ *http.Request) {
+			updates.Add(1)
+		},
+	)
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusOK,
+		body(testSensor),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	testCases := []struct {
+		name               string
+		mode               string
+		check              resource.TestCheckFunc
+		expectNonEmptyPlan bool
+		expectError        *regexp.Regexp
+	}{
+		{
+			name:        "read-only",
+			mode:        "read_only",
+			expectError: regexp.MustCompile(`client is read-only, request\s+not allowed`),
+		},
+		{
+			name: "dry run",
+			mode: "dry_run",
+			check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("greynoise_sensor_persona.this", "persona_id",
+					"601c5e5a-cf2e-4401-844a-04d4391b1332",
+				),
+				resource.TestCheckResourceAttr("greynoise_sensor_persona.this", "persona_status", "pending"),
+				resource.TestCheckNoResourceAttr("greynoise_sensor_persona.this", "applied_at"),
+			),
+			// The sensor keeps its persona, so the refresh plans the update again.
+			expectNonEmptyPlan: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(`
+							provider "greynoise" {
+							  base_url = "%s"
+							  api_key  = "%s"
+							  %s       = true
+							}
+
+							resource "greynoise_sensor_persona" "this" {
+							  sensor_id  = "2d6aed11-f2de-48f9-9526-8fb72be10700"
+							  persona_id = "601c5e5a-cf2e-4401-844a-04d4391b1332"
+							}
+							`, server.URL, mockAPIKey, tc.mode),
+						Check:              tc.check,
+						ExpectNonEmptyPlan: tc.expectNonEmptyPlan,
+						ExpectError:        tc.expectError,
+					},
+				},
+			})
+		})
+	}
+
+	t.Cleanup(func() {
+		assert.Zero(t, updates.Load(), "sensor should not be updated")
+	})
+}
+
+func TestAccSensorPersonaResource_AuditLog(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "3d6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Quiet Lynx",
+		PublicIps: []string{
+			"159.223.200.219",
+		},
+		Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+	}
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+	sensorPath := fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID)
+
+	mockServer.Register(http.MethodPut, sensorPath, http.StatusAccepted, emptyBody,
+		func(r *http.Request) {
+			var req client.SensorUpdateRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			testSensor.Persona = req.Persona
+		},
+	)
+	mockServer.Register(http.MethodGet, sensorPath, http.StatusOK, body(testSensor), nil)
+
+	server := mockServer.Server()
+	auditLogPath := filepath.Join(t.TempDir(), "audit.log")
+
+	config := func(personaID string) string {
+		return fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url       = "%s"
+			  api_key        = "%s"
+			  audit_log_path = "%s"
+			}
+
+			resource "greynoise_sensor_persona" "this" {
+			  sensor_id  = "3d6aed11-f2de-48f9-9526-8fb72be10700"
+			  persona_id = "%s"
+			}
+			`, server.URL, mockAPIKey, auditLogPath, personaID)
+	}
+
+	checkAuditLog := func(personaIDs ...string) resource.TestCheckFunc {
+		return func(_ *terraform.State) error {
+			content, err := os.ReadFile(auditLogPath)
+			if err != nil {
+				return err
+			}
+
+			lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+			if len(lines) != len(personaIDs) {
+				return fmt.Errorf("expected %d audit log entries, got: %d", len(personaIDs), len(lines))
+			}
+
+			for i, line := range lines {
+				var entry client.AuditEntry
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					return err
+				}
+
+				if entry.Method != http.MethodPut || entry.Path != sensorPath ||
+					entry.SensorID != testSensor.ID || entry.Status != http.StatusAccepted {
+					return fmt.Errorf("unexpected audit log entry: %s", line)
+				}
+
+				var req client.SensorUpdateRequest
+				if err := json.Unmarshal(entry.Request, &req); err != nil {
+					return err
+				}
+
+				if req.Persona != personaIDs[i] {
+					return fmt.Errorf("expected persona %s in audit log entry, got: %s", personaIDs[i], req.Persona)
+				}
+			}
+
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("501c5e5a-cf2e-4401-844a-04d4391b1332"),
+				Check:  checkAuditLog("501c5e5a-cf2e-4401-844a-04d4391b1332"),
+			},
+			{
+				Config: config("601c5e5a-cf2e-4401-844a-04d4391b1332"),
+				Check: checkAuditLog(
+					"501c5e5a-cf2e-4401-844a-04d4391b1332",
+					"601c5e5a-cf2e-4401-844a-04d4391b1332",
+				),
+			},
+		},
+	})
+}
+
+func TestAccSensorPersonaResource_DefaultMetadata(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "5d6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Bold Marten",
+		PublicIps: []string{
+			"159.223.200.221",
+		},
+		Persona: "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Metadata: client.SensorMetadata{
+			Items: []client.SensorMetadatum{
+				{
+					Access: client.MetadataAccessReadWrite,
+					Name:   "team",
+					Val:    "red",
+				},
+			},
+		},
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+	}
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	mockServer.Register(http.MethodPut,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusAccepted,
+		emptyBody,
+		func(r *http.Request) {
+			var req client.SensorUpdateRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			testSensor.Persona = req.Persona
+
+			if req.Metadata != nil {
+				testSensor.Metadata = *req.Metadata
+			}
+		},
+	)
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusOK,
+		body(testSensor),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	config := func(managedBy string) string {
+		return fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url = "%s"
+			  api_key  = "%s"
+
+			  default_metadata = {
+			    managed_by = "%s"
+			    team       = "blue"
+			  }
+			}
+
+			resource "greynoise_sensor_persona" "this" {
+			  sensor_id  = "5d6aed11-f2de-48f9-9526-8fb72be10700"
+			  persona_id = "601c5e5a-cf2e-4401-844a-04d4391b1332"
+			}
+			`, server.URL, mockAPIKey, managedBy)
+	}
+
+	checkSensorMetadata := func(managedBy string) resource.TestCheckFunc {
+		return func(_ *terraform.State) error {
+			// Defaults replace existing values.
+			for name, val := range map[string]string{"managed_by": managedBy, "team": "blue"} {
+				if actual, _ := testSensor.Metadata.Get(name); actual != val {
+					return fmt.Errorf("expected sensor metadata %s to be %q, got: %q", name, val, actual)
+				}
+			}
+
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("terraform"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectKnownValue("greynoise_sensor_persona.this",
+							tfjsonpath.New("default_metadata"),
+							knownvalue.MapExact(map[string]knownvalue.Check{
+								"managed_by": knownvalue.StringExact("terraform"),
+								"team":       knownvalue.StringExact("blue"),
+							}),
+						),
+					},
+				},
+				Check: checkSensorMetadata("terraform"),
+			},
+			{
+				Config: config("platform"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("greynoise_sensor_persona.this",
+							plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("greynoise_sensor_persona.this",
+							tfjsonpath.New("default_metadata").AtMapKey("managed_by"),
+							knownvalue.StringExact("platform"),
+						),
+					},
+				},
+				Check: checkSensorMetadata("platform"),
+			},
+		},
+	})
+}
+
+func TestAccSensorPersonaResource_PersonaPolicy(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "6d6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Keen Otter",
+		PublicIps: []string{
+			"159.223.200.222",
+		},
+		Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+	}
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	var updates atomic.Int32
+
+	mockServer.Register(http.MethodPut,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusAccepted,
+		emptyBody,
+		func(_ *http.Request) {
+			updates.Add(1)
+		},
+	)
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusOK,
+		body(testSensor),
+		nil,
+	)
+	mockServer.Register(http.MethodGet, "/v1/personas/601c5e5a-cf2e-4401-844a-04d4391b1332",
+		http.StatusOK,
+		body(client.Persona{
+			ID:         "601c5e5a-cf2e-4401-844a-04d4391b1332",
+			Name:       "Cisco ASA",
+			Tier:       "enterprise",
+			Categories: []string{"vpn", "high-interaction"},
+		}),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	testCases := []struct {
+		name        string
+		policy      string
+		expectError *regexp.Regexp
+	}{
+		{
+			name:        "persona not allowed",
+			policy:      `allowed_persona_ids = ["501c5e5a-cf2e-4401-844a-04d4391b1332"]`,
+			expectError: regexp.MustCompile(`is not in\s+allowed_persona_ids`),
+		},
+		{
+			name:        "tier not allowed",
+			policy:      `allowed_tiers = ["community"]`,
+			expectError: regexp.MustCompile(`is of tier\s+"enterprise", allowed tiers: community`),
+		},
+		{
+			name:        "category denied",
+			policy:      `denied_categories = ["high-interaction"]`,
+			expectError: regexp.MustCompile(`is of denied\s+category "high-interaction"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(`
+							provider "greynoise" {
+							  base_url = "%s"
+							  api_key  = "%s"
+							  %s
+							}
+
+							resource "greynoise_sensor_persona" "this" {
+							  sensor_id  = "6d6aed11-f2de-48f9-9526-8fb72be10700"
+							  persona_id = "601c5e5a-cf2e-4401-844a-04d4391b1332"
+							}
+							`, server.URL, mockAPIKey, tc.policy),
+						PlanOnly:    true,
+						ExpectError: tc.expectError,
+					},
+				},
+			})
+		})
+	}
+
+	t.Cleanup(func() {
+		assert.Zero(t, updates.Load(), "sensor should not be updated")
+	})
+}
+
+func TestAccSensorPersonaResource_OnDestroy(t *testing.T) {
+	t.Parallel()
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	testCases := []struct {
+		name            string
+		sensorID        string
+		policy          string
+		onDestroy       string
+		expectPersonaID string
+	}{
+		{
+			name:            "retain",
+			sensorID:        "ad6aed11-f2de-48f9-9526-8fb72be10700",
+			onDestroy:       `on_destroy = "retain"`,
+			expectPersonaID: "601c5e5a-cf2e-4401-844a-04d4391b1332",
+		},
+		{
+			name:            "restore",
+			sensorID:        "bd6aed11-f2de-48f9-9526-8fb72be10700",
+			onDestroy:       `on_destroy = "restore"`,
+			expectPersonaID: "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		},
+		{
+			name:            "restore persona not allowed by policy",
+			sensorID:        "1f6aed11-f2de-48f9-9526-8fb72be10700",
+			policy:          `allowed_persona_ids = ["601c5e5a-cf2e-4401-844a-04d4391b1332"]`,
+			onDestroy:       `on_destroy = "restore"`,
+			expectPersonaID: "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		},
+		{
+			name:     "default",
+			sensorID: "cd6aed11-f2de-48f9-9526-8fb72be10700",
+			onDestroy: `on_destroy            = "default"
+							  on_destroy_persona_id = "701c5e5a-cf2e-4401-844a-04d4391b1332"`,
+			expectPersonaID: "701c5e5a-cf2e-4401-844a-04d4391b1332",
+		},
+	}
+
+	sensors := map[string]*client.Sensor{}
+
+	for _, tc := range testCases {
+		testSensor := &client.Sensor{
+			ID:   tc.sensorID,
+			Name: "Patient Heron",
+			PublicIps: []string{
+				"159.223.200.224",
+			},
+			Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+			Status:    "healthy",
+			Disabled:  false,
+			LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+			CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+			UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+		}
+		sensors[tc.sensorID] = testSensor
+
+		mockServer.Register(http.MethodPut,
+			fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+			http.StatusAccepted,
+			emptyBody,
+			func(r *http.Request) {
+				var req client.SensorUpdateRequest
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				testSensor.Persona = req.Persona
+			},
+		)
+		mockServer.Register(http.MethodGet,
+			fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+			http.StatusOK,
+			body(testSensor),
+			nil,
+		)
+	}
+
+	mockServer.Register(http.MethodGet, "/v1/personas/601c5e5a-cf2e-4401-844a-04d4391b1332",
+		http.StatusOK,
+		body(client.Persona{
+			ID:   "601c5e5a-cf2e-4401-844a-04d4391b1332",
+			Name: "Cisco ASA",
+		}),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(`
+							provider "greynoise" {
+							  base_url = "%s"
+							  api_key  = "%s"
+							  %s
+							}
+
+							resource "greynoise_sensor_persona" "this" {
+							  sensor_id  = "%s"
+							  persona_id = "601c5e5a-cf2e-4401-844a-04d4391b1332"
+							  %s
+							}
+							`, server.URL, mockAPIKey, tc.policy, tc.sensorID, tc.onDestroy),
+						Check: resource.ComposeAggregateTestCheckFunc(
+							resource.TestCheckResourceAttr("greynoise_sensor_persona.this", "original_persona_id",
+								"501c5e5a-cf2e-4401-844a-04d4391b1332",
+							),
+						),
+					},
+				},
+				CheckDestroy: func(_ *terraform.State) error {
+					if persona := sensors[tc.sensorID].Persona; persona != tc.expectPersonaID {
+						return fmt.Errorf("expected persona %s after destroy, got %s", tc.expectPersonaID, persona)
+					}
+
+					return nil
+				},
+			})
+		})
+	}
+
+	t.Run("error - missing on_destroy_persona_id", func(t *testing.T) {
+		t.Parallel()
+
+		resource.Test(t, resource.TestCase{
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+						provider "greynoise" {
+						  base_url = "%s"
+						  api_key  = "%s"
+						}
+
+						resource "greynoise_sensor_persona" "this" {
+						  sensor_id  = "ad6aed11-f2de-48f9-9526-8fb72be10700"
+						  persona_id = "601c5e5a-cf2e-4401-844a-04d4391b1332"
+						  on_destroy = "default"
+						}
+						`, server.URL, mockAPIKey),
+					PlanOnly:    true,
+					ExpectError: regexp.MustCompile(`on_destroy_persona_id is required`),
+				},
+			},
+		})
+	})
+}
+
+func TestAccSensorPersonaResource_PersonaLookup(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "dd6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Curious Lynx",
+		PublicIps: []string{
+			"159.223.200.225",
+		},
+		Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+	}
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	mockServer.Register(http.MethodPut,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusAccepted,
+		emptyBody,
+		func(r *http.Request) {
+			var req client.SensorUpdateRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			testSensor.Persona = req.Persona
+			testSensor.UpdatedAt = testSensor.UpdatedAt.Add(time.Minute)
+		},
+	)
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusOK,
+		body(testSensor),
+		nil,
+	)
+
+	personaSearches := map[string][]client.Persona{
+		"cisco asa": {
+			{ID: "701c5e5a-cf2e-4401-844a-04d4391b1332", Name: "Cisco ASA Legacy"},
+			{ID: "601c5e5a-cf2e-4401-844a-04d4391b1332", Name: "Cisco ASA"},
+		},
+		"tomcat": {
+			{ID: "801c5e5a-cf2e-4401-844a-04d4391b1332", Name: "Apache Tomcat"},
+		},
+		"RDP Server": {
+			{ID: "901c5e5a-cf2e-4401-844a-04d4391b1332", Name: "RDP Server"},
+			{ID: "a01c5e5a-cf2e-4401-844a-04d4391b1332", Name: "RDP server"},
+		},
+		"nothing": {},
+	}
+	for search, personas := range personaSearches {
+		search := search
+		mockServer.RegisterMatch(http.MethodGet, "/v1/personas",
+			func(url *url.URL) bool {
+				return url.Query().Get("search") == search
+			},
+			http.StatusOK,
+			body(client.PersonaSearchResponse{
+				Items: personas,
+				Pagination: client.Pagination{
+					Page:       1,
+					PageSize:   100,
+					TotalItems: int32(len(personas)),
+				},
+			}),
+			nil,
+		)
+	}
+
+	// The persona named "ssh" is on the second page of the search.
+	sshPages := [][]client.Persona{
+		{{ID: "b01c5e5a-cf2e-4401-844a-04d4391b1332", Name: "SSH Bastion"}},
+		{{ID: "c01c5e5a-cf2e-4401-844a-04d4391b1332", Name: "SSH"}},
+	}
+	for page, personas := range sshPages {
+		// The first page is requested without a page parameter.
+		pageParameter := ""
+		if page > 0 {
+			pageParameter = fmt.Sprint(page)
+		}
+
+		mockServer.RegisterMatch(http.MethodGet, "/v1/personas",
+			func(url *url.URL) bool {
+				return url.Query().Get("search") == "ssh" && url.Query().Get("page") == pageParameter
+			},
+			http.StatusOK,
+			body(client.PersonaSearchResponse{
+				Items: personas,
+				Pagination: client.Pagination{
+					Page:       int32(page),
+					PageSize:   1,
+					TotalItems: int32(len(sshPages)),
+				},
+			}),
+			nil,
+		)
+	}
+
+	server := mockServer.Server()
+
+	config := func(persona string) string {
+		return fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url = "%s"
+			  api_key  = "%s"
+			}
+
+			resource "greynoise_sensor_persona" "this" {
+			  sensor_id = "dd6aed11-f2de-48f9-9526-8fb72be10700"
+			  %s
+			}
+			`, server.URL, mockAPIKey, persona)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(`
+					persona_id   = "601c5e5a-cf2e-4401-844a-04d4391b1332"
+					persona_name = "cisco asa"`),
+				ExpectError: regexp.MustCompile(`Exactly one of persona_id, persona_name or persona_search must be set`),
+			},
+			{
+				Config: config(`persona_name = "cisco asa"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_persona.this", "persona_id",
+						"601c5e5a-cf2e-4401-844a-04d4391b1332",
+					),
+				),
+			},
+			{
+				Config: config(`
+					persona_search {
+					  search = "tomcat"
+					}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_persona.this", "persona_id",
+						"801c5e5a-cf2e-4401-844a-04d4391b1332",
+					),
+				),
+			},
+			// The persona changed outside of Terraform is resolved and applied again.
+			{
+				PreConfig: func() {
+					testSensor.Persona = "501c5e5a-cf2e-4401-844a-04d4391b1332"
+				},
+				Config: config(`
+					persona_search {
+					  search = "tomcat"
+					}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_persona.this", "persona_id",
+						"801c5e5a-cf2e-4401-844a-04d4391b1332",
+					),
+				),
+			},
+			{
+				Config: config(`persona_name = "ssh"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_persona.this", "persona_id",
+						"c01c5e5a-cf2e-4401-844a-04d4391b1332",
+					),
+				),
+			},
+			{
+				Config:      config(`persona_name = "RDP Server"`),
+				ExpectError: regexp.MustCompile(`2 personas named "RDP Server", expected exactly one`),
+			},
+			{
+				Config: config(`
+					persona_search {
+					  search = "cisco asa"
+					}`),
+				ExpectError: regexp.MustCompile(`2 personas matching the search, expected exactly one`),
+			},
+			{
+				Config: config(`
+					persona_search {
+					  search = "nothing"
+					}`),
+				ExpectError: regexp.MustCompile(`no persona matching the search`),
+			},
+		},
+	})
+}
+
+func TestAccSensorPersonaResource_Import(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "7e6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Imported Falcon",
+		PublicIps: []string{
+			"159.223.200.228",
+		},
+		Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+	}
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	mockServer.Register(http.MethodPut,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusAccepted,
+		emptyBody,
+		func(r *http.Request) {
+			var req client.SensorUpdateRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			testSensor.Persona = req.Persona
+		},
+	)
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusOK,
+		body(testSensor),
+		nil,
+	)
+
+	// Search results include partial matches, such as sensors with a similar name.
+	similarSensor := client.Sensor{
+		ID:        "8e6aed11-f2de-48f9-9526-8fb72be10700",
+		Name:      "Imported Falcon 2",
+		PublicIps: []string{"159.223.200.229"},
+	}
+	twinSensors := []client.Sensor{
+		{ID: "9e6aed11-f2de-48f9-9526-8fb72be10700", Name: "Twin Sensor"},
+		{ID: "ae6aed11-f2de-48f9-9526-8fb72be10700", Name: "twin sensor"},
+	}
+	sensorSearches := map[string][]client.Sensor{
+		"159.223.200.228": {*testSensor},
+		"imported falcon": {similarSensor, *testSensor},
+		"Twin Sensor":     twinSensors,
+		"Nobody":          {},
+	}
+	for filter, sensors := range sensorSearches {
+		filter := filter
+		mockServer.RegisterMatch(http.MethodGet,
+			fmt.Sprintf("/v1/workspaces/%s/sensors", mockWorkspaceID),
+			func(url *url.URL) bool {
+				return url.Query().Get("filter") == filter
+			},
+			http.StatusOK,
+			body(client.SensorSearchResponse{
+				Items: sensors,
+				Pagination: client.Pagination{
+					Page:       1,
+					PageSize:   100,
+					TotalItems: int32(len(sensors)),
+				},
+			}),
+			nil,
+		)
+	}
+
+	server := mockServer.Server()
+
+	config := fmt.Sprintf(`
+		provider "greynoise" {
+		  base_url = "%s"
+		  api_key  = "%s"
+		}
+
+		resource "greynoise_sensor_persona" "this" {
+		  sensor_id  = "7e6aed11-f2de-48f9-9526-8fb72be10700"
+		  persona_id = "601c5e5a-cf2e-4401-844a-04d4391b1332"
+		}
+		`, server.URL, mockAPIKey)
+
+	importStep := func(id string) resource.TestStep {
+		return resource.TestStep{
+			Config:                               config,
+			ResourceName:                         "greynoise_sensor_persona.this",
+			ImportState:                          true,
+			ImportStateId:                        id,
+			ImportStateVerify:                    true,
+			ImportStateVerifyIdentifierAttribute: "sensor_id",
+			// The persona before the resource was created is only known on create.
+			ImportStateVerifyIgnore: []string{"timeouts", "original_persona_id"},
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			importStep("7e6aed11-f2de-48f9-9526-8fb72be10700"),
+			importStep("159.223.200.228"),
+			importStep("imported falcon"),
+			{
+				Config:        config,
+				ResourceName:  "greynoise_sensor_persona.this",
+				ImportState:   true,
+				ImportStateId: "Twin Sensor",
+				ExpectError:   regexp.MustCompile(`2 sensors match "Twin\s+Sensor", import by UUID instead`),
+			},
+			{
+				Config:        config,
+				ResourceName:  "greynoise_sensor_persona.this",
+				ImportState:   true,
+				ImportStateId: "Nobody",
+				ExpectError:   regexp.MustCompile(`no sensor with public IP or\s+name "Nobody"`),
+			},
+			{
+				Config:        config,
+				ResourceName:  "greynoise_sensor_persona.this",
+				ImportState:   true,
+				ImportStateId: "0f6aed11-f2de-48f9-9526-8fb72be10700",
+				ExpectError:   regexp.MustCompile(`no sensor with UUID\s+0f6aed11-f2de-48f9-9526-8fb72be10700`),
+			},
+		},
+	})
+}
+
+func TestAccSensorPersonaResource_APIErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		sensorID string
+		status   int
+		attempts int32
+		error    *regexp.Regexp
+	}{
+		{
+			name:     "unauthorized",
+			sensorID: "8f6aed11-f2de-48f9-9526-8fb72be10700",
+			status:   http.StatusUnauthorized,
+			attempts: 1,
+			error:    regexp.MustCompile(`(?s)Unauthorized.*invalid status code: 401.*Check that the API key is\s+valid`),
+		},
+		{
+			name:     "not found",
+			sensorID: "9f6aed11-f2de-48f9-9526-8fb72be10700",
+			status:   http.StatusNotFound,
+			attempts: 1,
+			error:    regexp.MustCompile(`(?s)Not found.*not found\. It may have been\s+removed\s+outside`),
+		},
+		{
+			name:     "rate limited",
+			sensorID: "af6aed11-f2de-48f9-9526-8fb72be10700",
+			status:   http.StatusTooManyRequests,
+			attempts: 4,
+			error:    regexp.MustCompile(`(?s)Transient error.*invalid status code: 429.*try again later`),
+		},
+		{
+			name:     "unavailable",
+			sensorID: "bf6aed11-f2de-48f9-9526-8fb72be10700",
+			status:   http.StatusServiceUnavailable,
+			attempts: 4,
+			error:    regexp.MustCompile(`(?s)Transient error.*invalid status code: 503.*try again later`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			testSensor := &client.Sensor{
+				ID:   tc.sensorID,
+				Name: "Brave Lynx",
+				PublicIps: []string{
+					"159.223.200.225",
+				},
+				Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+				Status:    "healthy",
+				Disabled:  false,
+				LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+				CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+			}
+
+			mockServer := defaultMockAPIServer()
+			mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+			mockAPIKey := mockServer.APIKey
+
+			mockServer.Register(http.MethodPut,
+				fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+				http.StatusAccepted,
+				emptyBody,
+				func(r *http.Request) {
+					var req client.SensorUpdateRequest
+					_ = json.NewDecoder(r.Body).Decode(&req)
+					testSensor.Persona = req.Persona
+				},
+			)
+			mockServer.Register(http.MethodGet,
+				fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+				http.StatusOK,
+				body(testSensor),
+				nil,
+			)
+
+			var (
+				failing  atomic.Bool
+				attempts atomic.Int32
+			)
+
+			// Updates fail with the status while failing, through the retries of the client.
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPut && failing.Load() {
+					attempts.Add(1)
+
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tc.status)
+
+					return
+				}
+
+				mockServer.Handler().ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			config := func(personaID string) string {
+				return fmt.Sprintf(`
+					provider "greynoise" {
+					  base_url = "%s"
+					  api_key  = "%s"
+					}
+
+					resource "greynoise_sensor_persona" "this" {
+					  sensor_id  = "%s"
+					  persona_id = "%s"
+					}
+					`, server.URL, mockAPIKey, testSensor.ID, personaID)
+			}
+
+			fail := func() {
+				failing.Store(true)
+				attempts.Store(0)
+			}
+
+			// Retried statuses are only reported once the retries are exhausted.
+			succeed := func() {
+				assert.Equal(t, tc.attempts, attempts.Load(), "update attempts")
+				failing.Store(false)
+			}
+
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						// Create.
+						PreConfig:   fail,
+						Config:      config("601c5e5a-cf2e-4401-844a-04d4391b1332"),
+						ExpectError: tc.error,
+					},
+					{
+						PreConfig: succeed,
+						Config:    config("601c5e5a-cf2e-4401-844a-04d4391b1332"),
+					},
+					{
+						// Update.
+						PreConfig:   fail,
+						Config:      config("701c5e5a-cf2e-4401-844a-04d4391b1332"),
+						ExpectError: tc.error,
+					},
+					{
+						PreConfig: succeed,
+						Config:    config("601c5e5a-cf2e-4401-844a-04d4391b1332"),
+					},
+				},
+			})
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &SensorPersonaRotationResource{}
+var _ resource.ResourceWithModifyPlan = &SensorPersonaRotationResource{}
+var _ resource.ResourceWithValidateConfig = &SensorPersonaRotationResource{}
+
+func NewSensorPersonaRotationResource() resource.Resource {
+	return &SensorPersonaRotationResource{}
+}
+
+type SensorPersonaRotationResource struct {
+	data *Data
+}
+
+type SensorPersonaRotationResourceModel struct {
+	SensorID       types.String `tfsdk:"sensor_id"`
+	PersonaIDs     types.List   `tfsdk:"persona_ids"`
+	RotationPeriod types.String `tfsdk:"rotation_period"`
+	StartTime      types.String `tfsdk:"start_time"`
+
+	ActivePersonaID types.String `tfsdk:"active_persona_id"`
+	ActiveIndex     types.Int32  `tfsdk:"active_index"`
+	NextRotationAt  types.String `tfsdk:"next_rotation_at"`
+	PersonaStatus   types.String `tfsdk:"persona_status"`
+	AppliedAt       types.String `tfsdk:"applied_at"`
+	UpdatedAt       types.String `tfsdk:"updated_at"`
+
+	DefaultMetadata types.Map `tfsdk:"default_metadata"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (r *SensorPersonaRotationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_sensor_persona_rotation"
+}
+
+func (r *SensorPersonaRotationResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Sensor persona rotation resource is used to rotate the persona deployed to a sensor on a schedule.
+
+The persona active is computed during plan from ` + "`start_time`" + `: the first persona of ` + "`persona_ids`" + ` is active for the first ` + "`rotation_period`" + `, then the next one and so on, wrapping around. A plan after ` + "`next_rotation_at`" + ` updates the sensor, so that a scheduled ` + "`terraform apply`" + ` rotates the persona. Destroying the resource leaves the active persona deployed to the sensor.`,
+		Attributes: map[string]schema.Attribute{
+			"sensor_id": schema.StringAttribute{
+				MarkdownDescription: "UUID of the sensor.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"persona_ids": schema.ListAttribute{
+				MarkdownDescription: "Persona IDs to rotate, in order.",
+				ElementType:         types.StringType,
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"rotation_period": schema.StringAttribute{
+				MarkdownDescription: "Time each persona is active, as a duration such as `168h` for a week.",
+				Required:            true,
+			},
+			"start_time": schema.StringAttribute{
+				MarkdownDescription: "Time the rotation starts, in RFC 3339 format.",
+				Required:            true,
+			},
+			"active_persona_id": schema.StringAttribute{
+				MarkdownDescription: "Persona ID active on the sensor.",
+				Computed:            true,
+			},
+			"active_index": schema.Int32Attribute{
+				MarkdownDescription: "Index of the active persona in `persona_ids`.",
+				Computed:            true,
+			},
+			"next_rotation_at": schema.StringAttribute{
+				MarkdownDescription: "Time the next persona becomes active, in RFC 3339 format.",
+				Computed:            true,
+			},
+			"persona_status": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Status of the persona on the sensor: `%s` once the sensor "+
+					"runs it, or `%s` if it was not waited for, e.g. with `dry_run`, until applied again "+
+					"out of dry-run mode.",
+					PersonaStatusApplied, PersonaStatusPending),
+				Computed: true,
+			},
+			"applied_at": schema.StringAttribute{
+				MarkdownDescription: "Time the persona was applied to the sensor, in RFC 3339 format.",
+				Computed:            true,
+			},
+			"updated_at": schema.StringAttribute{
+				MarkdownDescription: "Time the sensor was last updated, in RFC 3339 format. Updates fail " +
+					"with a conflict if the sensor was modified outside of Terraform since.",
+				Computed: true,
+			},
+			"default_metadata": defaultMetadataAttribute(),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+			}),
+		},
+	}
+}
+
+func (r *SensorPersonaRotationResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*Data)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("expected *Data, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.data = data
+}
+
+func (r *SensorPersonaRotationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SensorPersonaRotationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	if err := r.update(ctx, &data, types.StringNull()); err != nil {
+		addOperationError(&resp.Diagnostics, "applying persona to sensor", err)
+
+		return
+	}
+
+	tflog.Trace(ctx, "Created sensor persona rotation resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SensorPersonaRotationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SensorPersonaRotationResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	sensor, err := r.data.Client.GetSensor(ctx, data.SensorID.ValueString())
+	if removeNotFoundSensor(ctx, resp, data.SensorID.ValueString(), err) {
+		return
+	}
+	if err != nil {
+		addOperationError(&resp.Diagnostics, "getting sensor", err)
+
+		return
+	}
+
+	switch {
+	case sensor.Persona == data.ActivePersonaID.ValueString():
+		// A pending persona may have been applied since.
+		if data.PersonaStatus.ValueString() == PersonaStatusPending {
+			data.PersonaStatus = types.StringValue(PersonaStatusApplied)
+			data.AppliedAt = types.StringValue(sensor.UpdatedAt.Format(time.RFC3339))
+		}
+	case data.PersonaStatus.ValueString() == PersonaStatusPending:
+		// The sensor still runs the previous persona until the pending one is applied, which is not drift.
+	default:
+		data.ActivePersonaID = types.StringValue(sensor.Persona)
+	}
+
+	data.UpdatedAt = sensorUpdatedAt(sensor)
+
+	data.DefaultMetadata, diags = refreshMetadata(ctx, data.DefaultMetadata, sensor.Metadata)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SensorPersonaRotationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data SensorPersonaRotationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	var updatedAt types.String
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("updated_at"), &updatedAt)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.update(ctx, &data, updatedAt); err != nil {
+		addOperationError(&resp.Diagnostics, "applying persona to sensor", err)
+
+		return
+	}
+
+	tflog.Trace(ctx, "Updated sensor persona rotation resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SensorPersonaRotationResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// The active persona is left deployed to the sensor.
+}
+
+func (r *SensorPersonaRotationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data SensorPersonaRotationResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.StartTime.IsNull() && !data.StartTime.IsUnknown() {
+		if _, err := time.Parse(time.RFC3339, data.StartTime.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("start_time"), "Invalid start time",
+				fmt.Sprintf("start_time must be in RFC 3339 format: %s", err.Error()))
+		}
+	}
+
+	if !data.RotationPeriod.IsNull() && !data.RotationPeriod.IsUnknown() {
+		if period, err := time.ParseDuration(data.RotationPeriod.ValueString()); err != nil || period <= 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("rotation_period"), "Invalid rotation period",
+				"rotation_period must be a positive duration, such as 168h.")
+		}
+	}
+}
+
+func (r *SensorPersonaRotationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy or if the provider has not been configured.
+	if req.Plan.Raw.IsNull() || r.data == nil {
+		return
+	}
+
+	var data SensorPersonaRotationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	defaultMetadataChanged, diags := planDefaultMetadata(ctx, r.data, req.State, &resp.Plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The active persona is unknown until the rotation is.
+	if data.PersonaIDs.IsUnknown() || data.StartTime.IsUnknown() || data.RotationPeriod.IsUnknown() {
+		return
+	}
+
+	var personaIDs []types.String
+
+	resp.Diagnostics.Append(data.PersonaIDs.ElementsAs(ctx, &personaIDs, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids := make([]string, len(personaIDs))
+	for i, id := range personaIDs {
+		if id.IsUnknown() {
+			return
+		}
+
+		ids[i] = id.ValueString()
+	}
+
+	rotation, err := newPersonaRotation(ids, data.StartTime.ValueString(), data.RotationPeriod.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid rotation", err.Error())
+
+		return
+	}
+
+	index, next := rotation.Slot(time.Now())
+	personaID := types.StringValue(ids[index])
+	nextRotationAt := types.StringValue(next.Format(time.RFC3339))
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("active_persona_id"), personaID)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("active_index"), types.Int32Value(int32(index)))...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("next_rotation_at"), nextRotationAt)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The sensor is updated once the slot changes, its persona drifted or is still pending out of dry-run mode,
+	// or the default metadata changes, without any change to the configuration.
+	if !req.State.Raw.IsNull() {
+		var state SensorPersonaRotationResourceModel
+
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		pending := state.PersonaStatus.ValueString() == PersonaStatusPending && !r.data.Client.DryRun()
+
+		if !personaID.Equal(state.ActivePersonaID) || !nextRotationAt.Equal(state.NextRotationAt) || pending ||
+			defaultMetadataChanged {
+			resp.Diagnostics.Append(planPersonaUpdate(ctx, &resp.Plan)...)
+		}
+	}
+
+	for _, id := range ids {
+		if err := checkPersona(ctx, r.data, id); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("persona_ids"), "Persona not allowed", err.Error())
+		}
+	}
+}
+
+// update applies the active persona to the sensor and sets the computed attributes. If updatedAt is known,
+// the sensor is only updated if not modified since.
+func (r *SensorPersonaRotationResource) update(ctx context.Context, data *SensorPersonaRotationResourceModel,
+	updatedAt types.String) error {
+	sensor, applied, err := applyPersona(ctx, r.data, data.SensorID.ValueString(),
+		data.ActivePersonaID.ValueString(), r.data.DefaultMetadata, updatedAt)
+	if err != nil {
+		return err
+	}
+
+	if applied {
+		data.PersonaStatus = types.StringValue(PersonaStatusApplied)
+		data.AppliedAt = types.StringValue(sensor.UpdatedAt.Format(time.RFC3339))
+	} else {
+		data.PersonaStatus = types.StringValue(PersonaStatusPending)
+		data.AppliedAt = types.StringNull()
+	}
+
+	data.UpdatedAt = sensorUpdatedAt(sensor)
+	data.DefaultMetadata = defaultMetadataValue(r.data)
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+func TestAccSensorPersonaRotationResource(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "5e6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Restless Wren",
+		PublicIps: []string{
+			"159.223.200.226",
+		},
+		Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+	}
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	mockServer.Register(http.MethodPut,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusAccepted,
+		emptyBody,
+		func(r *http.Request) {
+			var req client.SensorUpdateRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			testSensor.Persona = req.Persona
+			testSensor.UpdatedAt = testSensor.UpdatedAt.Add(time.Minute)
+		},
+	)
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusOK,
+		body(testSensor),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	// Started two and a half hours ago, so that the active persona does not change during the test.
+	start := time.Now().Add(-150 * time.Minute).UTC().Truncate(time.Second)
+
+	config := func(period string) string {
+		return fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url = "%s"
+			  api_key  = "%s"
+			}
+
+			resource "greynoise_sensor_persona_rotation" "this" {
+			  sensor_id       = "5e6aed11-f2de-48f9-9526-8fb72be10700"
+			  persona_ids     = [
+			    "601c5e5a-cf2e-4401-844a-04d4391b1332",
+			    "701c5e5a-cf2e-4401-844a-04d4391b1332",
+			    "801c5e5a-cf2e-4401-844a-04d4391b1332",
+			  ]
+			  rotation_period = "%s"
+			  start_time      = "%s"
+			}
+			`, server.URL, mockAPIKey, period, start.Format(time.RFC3339))
+	}
+
+	checkSensorPersona := func(personaID string) resource.TestCheckFunc {
+		return func(_ *terraform.State) error {
+			if testSensor.Persona != personaID {
+				return fmt.Errorf("expected sensor persona %s, got %s", personaID, testSensor.Persona)
+			}
+
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config("1w"),
+				ExpectError: regexp.MustCompile(`rotation_period must be a positive duration`),
+			},
+			{
+				Config: config("1h"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_persona_rotation.this", "active_index", "2"),
+					resource.TestCheckResourceAttr("greynoise_sensor_persona_rotation.this", "active_persona_id",
+						"801c5e5a-cf2e-4401-844a-04d4391b1332",
+					),
+					resource.TestCheckResourceAttr("greynoise_sensor_persona_rotation.this", "next_rotation_at",
+						start.Add(3*time.Hour).Format(time.RFC3339),
+					),
+					resource.TestCheckResourceAttr("greynoise_sensor_persona_rotation.this", "persona_status",
+						PersonaStatusApplied,
+					),
+					checkSensorPersona("801c5e5a-cf2e-4401-844a-04d4391b1332"),
+				),
+			},
+			// A shorter period moves the rotation to the next slot, wrapping around.
+			{
+				Config: config("45m"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_persona_rotation.this", "active_index", "0"),
+					resource.TestCheckResourceAttr("greynoise_sensor_persona_rotation.this", "active_persona_id",
+						"601c5e5a-cf2e-4401-844a-04d4391b1332",
+					),
+					checkSensorPersona("601c5e5a-cf2e-4401-844a-04d4391b1332"),
+				),
+			},
+			// The persona changed outside of Terraform is applied again.
+			{
+				PreConfig: func() {
+					testSensor.Persona = "501c5e5a-cf2e-4401-844a-04d4391b1332"
+				},
+				Config: config("45m"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					checkSensorPersona("601c5e5a-cf2e-4401-844a-04d4391b1332"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSensorPersonaRotationResource_DryRun(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "3f6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Drowsy Finch",
+		PublicIps: []string{
+			"159.223.200.228",
+		},
+		Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 0, time.UTC),
+	}
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	mockServer.Register(http.MethodPut,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusAccepted,
+		emptyBody,
+		func(r *http.Request) {
+			var req client.SensorUpdateRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			testSensor.Persona = req.Persona
+			testSensor.UpdatedAt = testSensor.UpdatedAt.Add(time.Minute)
+		},
+	)
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusOK,
+		body(testSensor),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	start := time.Now().Add(-30 * time.Minute).UTC().Truncate(time.Second)
+
+	config := func(dryRun bool) string {
+		return fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url = "%s"
+			  api_key  = "%s"
+			  dry_run  = %t
+			}
+
+			resource "greynoise_sensor_persona_rotation" "this" {
+			  sensor_id       = "3f6aed11-f2de-48f9-9526-8fb72be10700"
+			  persona_ids     = ["601c5e5a-cf2e-4401-844a-04d4391b1332"]
+			  rotation_period = "24h"
+			  start_time      = "%s"
+			}
+			`, server.URL, mockAPIKey, dryRun, start.Format(time.RFC3339))
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The pending persona is kept, rather than planned again on every refresh.
+			{
+				Config: config(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_persona_rotation.this", "active_persona_id",
+						"601c5e5a-cf2e-4401-844a-04d4391b1332",
+					),
+					resource.TestCheckResourceAttr("greynoise_sensor_persona_rotation.this", "persona_status",
+						PersonaStatusPending,
+					),
+				),
+			},
+			// The pending persona is applied out of dry-run mode.
+			{
+				Config: config(false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_persona_rotation.this", "persona_status",
+						PersonaStatusApplied,
+					),
+					func(_ *terraform.State) error {
+						if testSensor.Persona != "601c5e5a-cf2e-4401-844a-04d4391b1332" {
+							return fmt.Errorf("expected sensor persona 601c5e5a-cf2e-4401-844a-04d4391b1332, got %s",
+								testSensor.Persona)
+						}
+
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
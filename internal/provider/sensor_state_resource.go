@@ -0,0 +1,282 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+var _ resource.Resource = &SensorStateResource{}
+var _ resource.ResourceWithImportState = &SensorStateResource{}
+var _ resource.ResourceWithModifyPlan = &SensorStateResource{}
+
+func NewSensorStateResource() resource.Resource {
+	return &SensorStateResource{}
+}
+
+type SensorStateResource struct {
+	data *Data
+}
+
+type SensorStateResourceModel struct {
+	SensorID  types.String `tfsdk:"sensor_id"`
+	Disabled  types.Bool   `tfsdk:"disabled"`
+	Status    types.String `tfsdk:"status"`
+	UpdatedAt types.String `tfsdk:"updated_at"`
+
+	DefaultMetadata types.Map `tfsdk:"default_metadata"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (r *SensorStateResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_sensor_state"
+}
+
+func (r *SensorStateResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Sensor state resource is used to disable or enable a sensor.
+
+Sensors disabled or enabled outside of Terraform are reported as drift. Destroying the resource leaves the sensor as is, set ` + "`disabled = false`" + ` to enable it again first.`,
+		Attributes: map[string]schema.Attribute{
+			"sensor_id": schema.StringAttribute{
+				MarkdownDescription: "UUID of the sensor.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"disabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the sensor is disabled.",
+				Required:            true,
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "Status of the sensor.",
+				Computed:            true,
+			},
+			"updated_at": schema.StringAttribute{
+				MarkdownDescription: "Time the sensor was last updated, in RFC 3339 format. Updates fail " +
+					"with a conflict if the sensor was modified outside of Terraform since.",
+				Computed: true,
+			},
+			"default_metadata": defaultMetadataAttribute(),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+			}),
+		},
+	}
+}
+
+func (r *SensorStateResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*Data)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("expected *Data, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.data = data
+}
+
+func (r *SensorStateResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SensorStateResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	if err := r.update(ctx, &data, types.StringNull()); err != nil {
+		addOperationError(&resp.Diagnostics, "updating sensor state", err)
+
+		return
+	}
+
+	tflog.Trace(ctx, "Created sensor state resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SensorStateResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SensorStateResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	sensor, err := r.data.Client.GetSensor(ctx, data.SensorID.ValueString())
+	if removeNotFoundSensor(ctx, resp, data.SensorID.ValueString(), err) {
+		return
+	}
+	if err != nil {
+		addOperationError(&resp.Diagnostics, "getting sensor", err)
+
+		return
+	}
+
+	data.Disabled = types.BoolValue(sensor.Disabled)
+	data.Status = types.StringValue(sensor.Status)
+	data.UpdatedAt = sensorUpdatedAt(sensor)
+
+	data.DefaultMetadata, diags = refreshMetadata(ctx, data.DefaultMetadata, sensor.Metadata)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SensorStateResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data SensorStateResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := data.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	var updatedAt types.String
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("updated_at"), &updatedAt)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.update(ctx, &data, updatedAt); err != nil {
+		addOperationError(&resp.Diagnostics, "updating sensor state", err)
+
+		return
+	}
+
+	tflog.Trace(ctx, "Updated sensor state resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SensorStateResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// The sensor is left disabled or enabled.
+}
+
+func (r *SensorStateResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy or if the provider has not been configured.
+	if req.Plan.Raw.IsNull() || r.data == nil {
+		return
+	}
+
+	defaultMetadataChanged, diags := planDefaultMetadata(ctx, r.data, req.State, &resp.Plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The sensor is updated once the default metadata changes, without any change to the configuration.
+	if defaultMetadataChanged {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("status"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("updated_at"), types.StringUnknown())...)
+	}
+}
+
+func (r *SensorStateResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	sensor := importSensorState(ctx, r.data, req, resp)
+	if sensor == nil {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("disabled"), sensor.Disabled)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("status"), sensor.Status)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("updated_at"), sensorUpdatedAt(sensor))...)
+}
+
+// update disables or enables the sensor, setting the provider default metadata, and waits for the change
+// to be applied. If updatedAt is known, the sensor is only updated if not modified since.
+func (r *SensorStateResource) update(ctx context.Context, data *SensorStateResourceModel,
+	updatedAt types.String) error {
+	unlock, err := r.data.Client.LockSensor(ctx, data.SensorID.ValueString())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	metadata, err := sensorMetadataUpdate(ctx, r.data.Client, data.SensorID.ValueString(), r.data.DefaultMetadata)
+	if err != nil {
+		return err
+	}
+
+	disabled := data.Disabled.ValueBool()
+
+	if err := updateSensor(ctx, r.data.Client, data.SensorID.ValueString(), client.SensorUpdateRequest{
+		Metadata: metadata,
+		Disabled: &disabled,
+	}, updatedAt); err != nil {
+		return err
+	}
+
+	var sensor *client.Sensor
+
+	if r.data.Client.DryRun() {
+		sensor, err = r.data.Client.GetSensor(ctx, data.SensorID.ValueString())
+	} else {
+		// Changes are applied asynchronously, wait for them so that the next refresh does not report drift.
+		sensor, err = r.data.Client.WaitForSensor(ctx, data.SensorID.ValueString(), func(sensor *client.Sensor) bool {
+			return sensor.Disabled == disabled
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	data.Status = types.StringValue(sensor.Status)
+	data.UpdatedAt = sensorUpdatedAt(sensor)
+	data.DefaultMetadata = defaultMetadataValue(r.data)
+
+	return nil
+}
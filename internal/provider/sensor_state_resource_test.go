@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+func TestAccSensorStateResource(t *testing.T) {
+	t.Parallel()
+
+	testSensor := &client.Sensor{
+		ID:   "6e6aed11-f2de-48f9-9526-8fb72be10700",
+		Name: "Quiet Marten",
+		PublicIps: []string{
+			"159.223.200.227",
+		},
+		Persona:   "501c5e5a-cf2e-4401-844a-04d4391b1332",
+		Status:    "healthy",
+		Disabled:  false,
+		LastSeen:  time.Date(2024, 8, 27, 16, 27, 2, 0, time.UTC),
+		CreatedAt: time.Date(2024, 8, 10, 3, 2, 22, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 8, 26, 13, 53, 07, 250000000, time.UTC),
+	}
+
+	// Whether the sensor is modified outside of Terraform after each read.
+	modifiedOutside := false
+
+	mockServer := defaultMockAPIServer()
+	mockWorkspaceID := mockServer.Account.WorkspaceID.String()
+	mockAPIKey := mockServer.APIKey
+
+	mockServer.Register(http.MethodPut,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusAccepted,
+		emptyBody,
+		func(r *http.Request) {
+			var req client.SensorUpdateRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Disabled != nil {
+				testSensor.Disabled = *req.Disabled
+			}
+			testSensor.UpdatedAt = testSensor.UpdatedAt.Add(time.Minute)
+		},
+	)
+	mockServer.Register(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors/%s", mockWorkspaceID, testSensor.ID),
+		http.StatusOK,
+		body(testSensor),
+		func(*http.Request) {
+			if modifiedOutside {
+				testSensor.UpdatedAt = testSensor.UpdatedAt.Add(time.Millisecond)
+			}
+		},
+	)
+
+	mockServer.RegisterMatch(http.MethodGet,
+		fmt.Sprintf("/v1/workspaces/%s/sensors", mockWorkspaceID),
+		func(url *url.URL) bool {
+			return url.Query().Get("filter") == testSensor.PublicIps[0]
+		},
+		http.StatusOK,
+		body(client.SensorSearchResponse{
+			Items: []client.Sensor{*testSensor},
+			Pagination: client.Pagination{
+				Page:       1,
+				PageSize:   100,
+				TotalItems: 1,
+			},
+		}),
+		nil,
+	)
+
+	server := mockServer.Server()
+
+	config := func(disabled bool) string {
+		return fmt.Sprintf(`
+			provider "greynoise" {
+			  base_url = "%s"
+			  api_key  = "%s"
+			}
+
+			resource "greynoise_sensor_state" "this" {
+			  sensor_id = "6e6aed11-f2de-48f9-9526-8fb72be10700"
+			  disabled  = %t
+			}
+			`, server.URL, mockAPIKey, disabled)
+	}
+
+	checkSensorDisabled := func(disabled bool) resource.TestCheckFunc {
+		return func(_ *terraform.State) error {
+			if testSensor.Disabled != disabled {
+				return fmt.Errorf("expected sensor disabled %t, got %t", disabled, testSensor.Disabled)
+			}
+
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_state.this", "disabled", "true"),
+					resource.TestCheckResourceAttr("greynoise_sensor_state.this", "status", "healthy"),
+					checkSensorDisabled(true),
+				),
+			},
+			{
+				Config: config(false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_state.this", "disabled", "false"),
+					checkSensorDisabled(false),
+				),
+			},
+			// The sensor disabled outside of Terraform is enabled again.
+			{
+				PreConfig: func() {
+					testSensor.Disabled = true
+				},
+				Config: config(false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("greynoise_sensor_state.this", "disabled", "false"),
+					checkSensorDisabled(false),
+				),
+			},
+			{
+				Config:                               config(false),
+				ResourceName:                         "greynoise_sensor_state.this",
+				ImportState:                          true,
+				ImportStateId:                        "159.223.200.227",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "sensor_id",
+				ImportStateVerifyIgnore:              []string{"timeouts"},
+			},
+			// The sensor modified outside of Terraform since last read is not overwritten.
+			{
+				PreConfig: func() {
+					modifiedOutside = true
+				},
+				Config:      config(true),
+				ExpectError: regexp.MustCompile(`(?s)Conflict.*modified outside of\s+Terraform since\s+last read`),
+				Check:       checkSensorDisabled(false),
+			},
+		},
+	})
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/GreyNoise-Intelligence/terraform-provider-greynoise/internal/client"
+)
+
+// updateSensor updates the sensor, if it was not modified since updatedAt when known, so that changes made
+// outside of Terraform since it was last read are not overwritten.
+func updateSensor(ctx context.Context, c *client.GreyNoiseClient, id string, request client.SensorUpdateRequest,
+	updatedAt types.String) error {
+	if updatedAt.IsNull() || updatedAt.IsUnknown() {
+		return c.UpdateSensor(ctx, id, request)
+	}
+
+	unmodifiedSince, err := time.Parse(time.RFC3339Nano, updatedAt.ValueString())
+	if err != nil {
+		return fmt.Errorf("invalid updated_at: %w", err)
+	}
+
+	return c.ConditionalUpdateSensor(ctx, id, request, unmodifiedSince)
+}
+
+// sensorUpdatedAt formats the last update time of a sensor for the updated_at attribute.
+func sensorUpdatedAt(sensor *client.Sensor) types.String {
+	return types.StringValue(sensor.UpdatedAt.Format(time.RFC3339Nano))
+}
+
+// applyPersona applies the persona to the sensor, setting its metadata to the values if any, and waits for
+// the persona to be applied, unless in dry-run mode. It returns the sensor and whether the persona was applied.
+// If updatedAt is known, the sensor is only updated if not modified since.
+func applyPersona(ctx context.Context, d *Data, sensorID, personaID string, metadata map[string]string,
+	updatedAt types.String) (*client.Sensor, bool, error) {
+	// Checked again in case the persona changed since the plan.
+	if err := checkPersona(ctx, d, personaID); err != nil {
+		return nil, false, err
+	}
+
+	return setPersona(ctx, d, sensorID, personaID, metadata, updatedAt)
+}
+
+// setPersona is applyPersona without checking the persona against the persona policy, for personas not
+// chosen in the configuration, e.g. the one the sensor had before it was managed by Terraform.
+func setPersona(ctx context.Context, d *Data, sensorID, personaID string, metadata map[string]string,
+	updatedAt types.String) (*client.Sensor, bool, error) {
+	unlock, err := d.Client.LockSensor(ctx, sensorID)
+	if err != nil {
+		return nil, false, err
+	}
+	defer unlock()
+
+	metadataUpdate, err := sensorMetadataUpdate(ctx, d.Client, sensorID, metadata)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := updateSensor(ctx, d.Client, sensorID, client.SensorUpdateRequest{
+		Persona:  personaID,
+		Metadata: metadataUpdate,
+	}, updatedAt); err != nil {
+		return nil, false, err
+	}
+
+	if d.Client.DryRun() {
+		// Nothing is applied in dry-run mode.
+		sensor, err := d.Client.GetSensor(ctx, sensorID)
+
+		return sensor, false, err
+	}
+
+	sensor, err := d.Client.WaitForSensor(ctx, sensorID, func(sensor *client.Sensor) bool {
+		return sensor.Persona == personaID
+	})
+
+	return sensor, err == nil, err
+}
+
+// planPersonaUpdate marks the attributes set when applying a persona as unknown, for plans updating the
+// persona, or the default metadata, without any change to the configuration.
+func planPersonaUpdate(ctx context.Context, plan *tfsdk.Plan) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, name := range []string{"persona_status", "applied_at", "updated_at"} {
+		diags.Append(plan.SetAttribute(ctx, path.Root(name), types.StringUnknown())...)
+	}
+
+	return diags
+}
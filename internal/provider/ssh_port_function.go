@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &SSHPortFunction{}
+
+func NewSSHPortFunction() function.Function {
+	return &SSHPortFunction{}
+}
+
+type SSHPortFunction struct{}
+
+func (f *SSHPortFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "ssh_port"
+}
+
+func (f *SSHPortFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "SSH port selected for a server",
+		MarkdownDescription: "Returns the SSH port that `greynoise_sensor_bootstrap` selects for a server " +
+			"when `ssh_port` is not set, e.g. to open it in a security group before the server is bootstrapped.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name: "public_ip",
+				MarkdownDescription: "Public IP(s) of the server, as set in `public_ip` of " +
+					"`greynoise_sensor_bootstrap`. Comma-separated list of IPs or CIDRs is acceptable.",
+				Validators: []function.StringParameterValidator{
+					publicIPParameterValidator{},
+				},
+			},
+		},
+		Return: function.Int32Return{},
+	}
+}
+
+func (f *SSHPortFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var publicIP string
+
+	resp.Error = req.Arguments.Get(ctx, &publicIP)
+	if resp.Error != nil {
+		return
+	}
+
+	publicIPs, err := parsePublicIP(publicIP)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, DeterministicSSHPort(publicIPs[0]))
+}
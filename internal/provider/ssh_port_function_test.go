@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestSSHPortFunction(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		publicIP    string
+		port        int64
+		expectError *regexp.Regexp
+	}{
+		{
+			name:     "single IP",
+			publicIP: "185.108.182.240",
+			port:     62914,
+		},
+		{
+			name:     "CIDR list",
+			publicIP: "179.108.182.240/32,172.108.182.241/32",
+			port:     58026,
+		},
+		{
+			name:        "invalid IP",
+			publicIP:    "invalid_ip",
+			expectError: regexp.MustCompile(`Error occurred while parsing IP:\s+invalid CIDR address: invalid_ip`),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			step := resource.TestStep{
+				Config: `
+				output "test" {
+				  value = provider::greynoise::ssh_port("` + tc.publicIP + `")
+				}`,
+				ExpectError: tc.expectError,
+			}
+
+			if tc.expectError == nil {
+				step.ConfigStateChecks = []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.Int64Exact(tc.port)),
+				}
+			}
+
+			resource.UnitTest(t, resource.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps:                    []resource.TestStep{step},
+			})
+		})
+	}
+}